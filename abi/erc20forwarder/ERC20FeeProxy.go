@@ -0,0 +1,390 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package erc20forwarder
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ERC20ForwardRequestTypesERC20ForwardRequest is an auto generated low-level Go binding around an user-defined struct.
+type ERC20ForwardRequestTypesERC20ForwardRequest struct {
+	From          common.Address
+	To            common.Address
+	Token         common.Address
+	TxGas         *big.Int
+	TokenGasPrice *big.Int
+	BatchId       *big.Int
+	BatchNonce    *big.Int
+	Deadline      *big.Int
+	Data          []byte
+}
+
+// ERC20FeeProxyMetaData contains all meta data concerning the ERC20FeeProxy contract.
+var ERC20FeeProxyMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"name\":\"baseGas\",\"outputs\":[{\"internalType\":\"uint128\",\"name\":\"\",\"type\":\"uint128\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"txGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"tokenGasPrice\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"batchId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"batchNonce\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"internalType\":\"structERC20ForwardRequestTypes.ERC20ForwardRequest\",\"name\":\"req\",\"type\":\"tuple\"},{\"internalType\":\"bytes32\",\"name\":\"domainSeparator\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"executeEIP712\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"ret\",\"type\":\"bytes\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"txGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"tokenGasPrice\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"batchId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"batchNonce\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"}],\"internalType\":\"structERC20ForwardRequestTypes.ERC20ForwardRequest\",\"name\":\"req\",\"type\":\"tuple\"},{\"internalType\":\"bytes\",\"name\":\"sig\",\"type\":\"bytes\"}],\"name\":\"executePersonalSign\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"ret\",\"type\":\"bytes\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"feeManager\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"feeReceiver\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"oracleAggregator\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"transferHandlerGas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// ERC20FeeProxyABI is the input ABI used to generate the binding from.
+// Deprecated: Use ERC20FeeProxyMetaData.ABI instead.
+var ERC20FeeProxyABI = ERC20FeeProxyMetaData.ABI
+
+// ERC20FeeProxy is an auto generated Go binding around an Ethereum contract.
+type ERC20FeeProxy struct {
+	ERC20FeeProxyCaller     // Read-only binding to the contract
+	ERC20FeeProxyTransactor // Write-only binding to the contract
+	ERC20FeeProxyFilterer   // Log filterer for contract events
+}
+
+// ERC20FeeProxyCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ERC20FeeProxyCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20FeeProxyTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ERC20FeeProxyTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20FeeProxyFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ERC20FeeProxyFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ERC20FeeProxySession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ERC20FeeProxySession struct {
+	Contract     *ERC20FeeProxy    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ERC20FeeProxyCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ERC20FeeProxyCallerSession struct {
+	Contract *ERC20FeeProxyCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// ERC20FeeProxyTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ERC20FeeProxyTransactorSession struct {
+	Contract     *ERC20FeeProxyTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// ERC20FeeProxyRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ERC20FeeProxyRaw struct {
+	Contract *ERC20FeeProxy // Generic contract binding to access the raw methods on
+}
+
+// ERC20FeeProxyCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ERC20FeeProxyCallerRaw struct {
+	Contract *ERC20FeeProxyCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ERC20FeeProxyTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ERC20FeeProxyTransactorRaw struct {
+	Contract *ERC20FeeProxyTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewERC20FeeProxy creates a new instance of ERC20FeeProxy, bound to a specific deployed contract.
+func NewERC20FeeProxy(address common.Address, backend bind.ContractBackend) (*ERC20FeeProxy, error) {
+	contract, err := bindERC20FeeProxy(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20FeeProxy{ERC20FeeProxyCaller: ERC20FeeProxyCaller{contract: contract}, ERC20FeeProxyTransactor: ERC20FeeProxyTransactor{contract: contract}, ERC20FeeProxyFilterer: ERC20FeeProxyFilterer{contract: contract}}, nil
+}
+
+// NewERC20FeeProxyCaller creates a new read-only instance of ERC20FeeProxy, bound to a specific deployed contract.
+func NewERC20FeeProxyCaller(address common.Address, caller bind.ContractCaller) (*ERC20FeeProxyCaller, error) {
+	contract, err := bindERC20FeeProxy(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20FeeProxyCaller{contract: contract}, nil
+}
+
+// NewERC20FeeProxyTransactor creates a new write-only instance of ERC20FeeProxy, bound to a specific deployed contract.
+func NewERC20FeeProxyTransactor(address common.Address, transactor bind.ContractTransactor) (*ERC20FeeProxyTransactor, error) {
+	contract, err := bindERC20FeeProxy(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20FeeProxyTransactor{contract: contract}, nil
+}
+
+// NewERC20FeeProxyFilterer creates a new log filterer instance of ERC20FeeProxy, bound to a specific deployed contract.
+func NewERC20FeeProxyFilterer(address common.Address, filterer bind.ContractFilterer) (*ERC20FeeProxyFilterer, error) {
+	contract, err := bindERC20FeeProxy(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20FeeProxyFilterer{contract: contract}, nil
+}
+
+// bindERC20FeeProxy binds a generic wrapper to an already deployed contract.
+func bindERC20FeeProxy(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(ERC20FeeProxyABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ERC20FeeProxy *ERC20FeeProxyRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ERC20FeeProxy.Contract.ERC20FeeProxyCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ERC20FeeProxy *ERC20FeeProxyRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ERC20FeeProxyTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ERC20FeeProxy *ERC20FeeProxyRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ERC20FeeProxyTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ERC20FeeProxy *ERC20FeeProxyCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ERC20FeeProxy.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ERC20FeeProxy *ERC20FeeProxyTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ERC20FeeProxy *ERC20FeeProxyTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.contract.Transact(opts, method, params...)
+}
+
+// BaseGas is a free data retrieval call binding the contract method 0x583bbc40.
+//
+// Solidity: function baseGas() view returns(uint128)
+func (_ERC20FeeProxy *ERC20FeeProxyCaller) BaseGas(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _ERC20FeeProxy.contract.Call(opts, &out, "baseGas")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BaseGas is a free data retrieval call binding the contract method 0x583bbc40.
+//
+// Solidity: function baseGas() view returns(uint128)
+func (_ERC20FeeProxy *ERC20FeeProxySession) BaseGas() (*big.Int, error) {
+	return _ERC20FeeProxy.Contract.BaseGas(&_ERC20FeeProxy.CallOpts)
+}
+
+// BaseGas is a free data retrieval call binding the contract method 0x583bbc40.
+//
+// Solidity: function baseGas() view returns(uint128)
+func (_ERC20FeeProxy *ERC20FeeProxyCallerSession) BaseGas() (*big.Int, error) {
+	return _ERC20FeeProxy.Contract.BaseGas(&_ERC20FeeProxy.CallOpts)
+}
+
+// FeeManager is a free data retrieval call binding the contract method 0xd0fb0203.
+//
+// Solidity: function feeManager() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCaller) FeeManager(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ERC20FeeProxy.contract.Call(opts, &out, "feeManager")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// FeeManager is a free data retrieval call binding the contract method 0xd0fb0203.
+//
+// Solidity: function feeManager() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxySession) FeeManager() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.FeeManager(&_ERC20FeeProxy.CallOpts)
+}
+
+// FeeManager is a free data retrieval call binding the contract method 0xd0fb0203.
+//
+// Solidity: function feeManager() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCallerSession) FeeManager() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.FeeManager(&_ERC20FeeProxy.CallOpts)
+}
+
+// FeeReceiver is a free data retrieval call binding the contract method 0xb3f00674.
+//
+// Solidity: function feeReceiver() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCaller) FeeReceiver(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ERC20FeeProxy.contract.Call(opts, &out, "feeReceiver")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// FeeReceiver is a free data retrieval call binding the contract method 0xb3f00674.
+//
+// Solidity: function feeReceiver() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxySession) FeeReceiver() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.FeeReceiver(&_ERC20FeeProxy.CallOpts)
+}
+
+// FeeReceiver is a free data retrieval call binding the contract method 0xb3f00674.
+//
+// Solidity: function feeReceiver() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCallerSession) FeeReceiver() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.FeeReceiver(&_ERC20FeeProxy.CallOpts)
+}
+
+// OracleAggregator is a free data retrieval call binding the contract method 0x8dffe3f4.
+//
+// Solidity: function oracleAggregator() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCaller) OracleAggregator(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _ERC20FeeProxy.contract.Call(opts, &out, "oracleAggregator")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// OracleAggregator is a free data retrieval call binding the contract method 0x8dffe3f4.
+//
+// Solidity: function oracleAggregator() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxySession) OracleAggregator() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.OracleAggregator(&_ERC20FeeProxy.CallOpts)
+}
+
+// OracleAggregator is a free data retrieval call binding the contract method 0x8dffe3f4.
+//
+// Solidity: function oracleAggregator() view returns(address)
+func (_ERC20FeeProxy *ERC20FeeProxyCallerSession) OracleAggregator() (common.Address, error) {
+	return _ERC20FeeProxy.Contract.OracleAggregator(&_ERC20FeeProxy.CallOpts)
+}
+
+// TransferHandlerGas is a free data retrieval call binding the contract method 0x6fbd8012.
+//
+// Solidity: function transferHandlerGas(address ) view returns(uint256)
+func (_ERC20FeeProxy *ERC20FeeProxyCaller) TransferHandlerGas(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _ERC20FeeProxy.contract.Call(opts, &out, "transferHandlerGas", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TransferHandlerGas is a free data retrieval call binding the contract method 0x6fbd8012.
+//
+// Solidity: function transferHandlerGas(address ) view returns(uint256)
+func (_ERC20FeeProxy *ERC20FeeProxySession) TransferHandlerGas(arg0 common.Address) (*big.Int, error) {
+	return _ERC20FeeProxy.Contract.TransferHandlerGas(&_ERC20FeeProxy.CallOpts, arg0)
+}
+
+// TransferHandlerGas is a free data retrieval call binding the contract method 0x6fbd8012.
+//
+// Solidity: function transferHandlerGas(address ) view returns(uint256)
+func (_ERC20FeeProxy *ERC20FeeProxyCallerSession) TransferHandlerGas(arg0 common.Address) (*big.Int, error) {
+	return _ERC20FeeProxy.Contract.TransferHandlerGas(&_ERC20FeeProxy.CallOpts, arg0)
+}
+
+// ExecuteEIP712 is a paid mutator transaction binding the contract method 0x41706c4e.
+//
+// Solidity: function executeEIP712((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes32 domainSeparator, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxyTransactor) ExecuteEIP712(opts *bind.TransactOpts, req ERC20ForwardRequestTypesERC20ForwardRequest, domainSeparator [32]byte, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.contract.Transact(opts, "executeEIP712", req, domainSeparator, sig)
+}
+
+// ExecuteEIP712 is a paid mutator transaction binding the contract method 0x41706c4e.
+//
+// Solidity: function executeEIP712((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes32 domainSeparator, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxySession) ExecuteEIP712(req ERC20ForwardRequestTypesERC20ForwardRequest, domainSeparator [32]byte, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ExecuteEIP712(&_ERC20FeeProxy.TransactOpts, req, domainSeparator, sig)
+}
+
+// ExecuteEIP712 is a paid mutator transaction binding the contract method 0x41706c4e.
+//
+// Solidity: function executeEIP712((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes32 domainSeparator, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxyTransactorSession) ExecuteEIP712(req ERC20ForwardRequestTypesERC20ForwardRequest, domainSeparator [32]byte, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ExecuteEIP712(&_ERC20FeeProxy.TransactOpts, req, domainSeparator, sig)
+}
+
+// ExecutePersonalSign is a paid mutator transaction binding the contract method 0x8171e632.
+//
+// Solidity: function executePersonalSign((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxyTransactor) ExecutePersonalSign(opts *bind.TransactOpts, req ERC20ForwardRequestTypesERC20ForwardRequest, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.contract.Transact(opts, "executePersonalSign", req, sig)
+}
+
+// ExecutePersonalSign is a paid mutator transaction binding the contract method 0x8171e632.
+//
+// Solidity: function executePersonalSign((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxySession) ExecutePersonalSign(req ERC20ForwardRequestTypesERC20ForwardRequest, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ExecutePersonalSign(&_ERC20FeeProxy.TransactOpts, req, sig)
+}
+
+// ExecutePersonalSign is a paid mutator transaction binding the contract method 0x8171e632.
+//
+// Solidity: function executePersonalSign((address,address,address,uint256,uint256,uint256,uint256,uint256,bytes) req, bytes sig) returns(bool success, bytes ret)
+func (_ERC20FeeProxy *ERC20FeeProxyTransactorSession) ExecutePersonalSign(req ERC20ForwardRequestTypesERC20ForwardRequest, sig []byte) (*types.Transaction, error) {
+	return _ERC20FeeProxy.Contract.ExecutePersonalSign(&_ERC20FeeProxy.TransactOpts, req, sig)
+}
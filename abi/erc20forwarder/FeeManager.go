@@ -0,0 +1,242 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package erc20forwarder
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// FeeManagerMetaData contains all meta data concerning the FeeManager contract.
+var FeeManagerMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"user\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"getFeeMultiplier\",\"outputs\":[{\"internalType\":\"uint16\",\"name\":\"\",\"type\":\"uint16\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"getTokenAllowed\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// FeeManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use FeeManagerMetaData.ABI instead.
+var FeeManagerABI = FeeManagerMetaData.ABI
+
+// FeeManager is an auto generated Go binding around an Ethereum contract.
+type FeeManager struct {
+	FeeManagerCaller     // Read-only binding to the contract
+	FeeManagerTransactor // Write-only binding to the contract
+	FeeManagerFilterer   // Log filterer for contract events
+}
+
+// FeeManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type FeeManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FeeManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type FeeManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FeeManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type FeeManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FeeManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type FeeManagerSession struct {
+	Contract     *FeeManager       // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// FeeManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type FeeManagerCallerSession struct {
+	Contract *FeeManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts     // Call options to use throughout this session
+}
+
+// FeeManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type FeeManagerTransactorSession struct {
+	Contract     *FeeManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts     // Transaction auth options to use throughout this session
+}
+
+// FeeManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type FeeManagerRaw struct {
+	Contract *FeeManager // Generic contract binding to access the raw methods on
+}
+
+// FeeManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type FeeManagerCallerRaw struct {
+	Contract *FeeManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// FeeManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type FeeManagerTransactorRaw struct {
+	Contract *FeeManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewFeeManager creates a new instance of FeeManager, bound to a specific deployed contract.
+func NewFeeManager(address common.Address, backend bind.ContractBackend) (*FeeManager, error) {
+	contract, err := bindFeeManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeManager{FeeManagerCaller: FeeManagerCaller{contract: contract}, FeeManagerTransactor: FeeManagerTransactor{contract: contract}, FeeManagerFilterer: FeeManagerFilterer{contract: contract}}, nil
+}
+
+// NewFeeManagerCaller creates a new read-only instance of FeeManager, bound to a specific deployed contract.
+func NewFeeManagerCaller(address common.Address, caller bind.ContractCaller) (*FeeManagerCaller, error) {
+	contract, err := bindFeeManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeManagerCaller{contract: contract}, nil
+}
+
+// NewFeeManagerTransactor creates a new write-only instance of FeeManager, bound to a specific deployed contract.
+func NewFeeManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*FeeManagerTransactor, error) {
+	contract, err := bindFeeManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeManagerTransactor{contract: contract}, nil
+}
+
+// NewFeeManagerFilterer creates a new log filterer instance of FeeManager, bound to a specific deployed contract.
+func NewFeeManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*FeeManagerFilterer, error) {
+	contract, err := bindFeeManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &FeeManagerFilterer{contract: contract}, nil
+}
+
+// bindFeeManager binds a generic wrapper to an already deployed contract.
+func bindFeeManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(FeeManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FeeManager *FeeManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FeeManager.Contract.FeeManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FeeManager *FeeManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FeeManager.Contract.FeeManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FeeManager *FeeManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FeeManager.Contract.FeeManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FeeManager *FeeManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FeeManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FeeManager *FeeManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FeeManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FeeManager *FeeManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FeeManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetFeeMultiplier is a free data retrieval call binding the contract method 0xdf5d4b8a.
+//
+// Solidity: function getFeeMultiplier(address user, address token) view returns(uint16)
+func (_FeeManager *FeeManagerCaller) GetFeeMultiplier(opts *bind.CallOpts, user common.Address, token common.Address) (uint16, error) {
+	var out []interface{}
+	err := _FeeManager.contract.Call(opts, &out, "getFeeMultiplier", user, token)
+
+	if err != nil {
+		return *new(uint16), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint16)).(*uint16)
+
+	return out0, err
+
+}
+
+// GetFeeMultiplier is a free data retrieval call binding the contract method 0xdf5d4b8a.
+//
+// Solidity: function getFeeMultiplier(address user, address token) view returns(uint16)
+func (_FeeManager *FeeManagerSession) GetFeeMultiplier(user common.Address, token common.Address) (uint16, error) {
+	return _FeeManager.Contract.GetFeeMultiplier(&_FeeManager.CallOpts, user, token)
+}
+
+// GetFeeMultiplier is a free data retrieval call binding the contract method 0xdf5d4b8a.
+//
+// Solidity: function getFeeMultiplier(address user, address token) view returns(uint16)
+func (_FeeManager *FeeManagerCallerSession) GetFeeMultiplier(user common.Address, token common.Address) (uint16, error) {
+	return _FeeManager.Contract.GetFeeMultiplier(&_FeeManager.CallOpts, user, token)
+}
+
+// GetTokenAllowed is a free data retrieval call binding the contract method 0x0955f0ee.
+//
+// Solidity: function getTokenAllowed(address token) view returns(bool)
+func (_FeeManager *FeeManagerCaller) GetTokenAllowed(opts *bind.CallOpts, token common.Address) (bool, error) {
+	var out []interface{}
+	err := _FeeManager.contract.Call(opts, &out, "getTokenAllowed", token)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// GetTokenAllowed is a free data retrieval call binding the contract method 0x0955f0ee.
+//
+// Solidity: function getTokenAllowed(address token) view returns(bool)
+func (_FeeManager *FeeManagerSession) GetTokenAllowed(token common.Address) (bool, error) {
+	return _FeeManager.Contract.GetTokenAllowed(&_FeeManager.CallOpts, token)
+}
+
+// GetTokenAllowed is a free data retrieval call binding the contract method 0x0955f0ee.
+//
+// Solidity: function getTokenAllowed(address token) view returns(bool)
+func (_FeeManager *FeeManagerCallerSession) GetTokenAllowed(token common.Address) (bool, error) {
+	return _FeeManager.Contract.GetTokenAllowed(&_FeeManager.CallOpts, token)
+}
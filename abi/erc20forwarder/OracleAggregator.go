@@ -0,0 +1,242 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package erc20forwarder
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// OracleAggregatorMetaData contains all meta data concerning the OracleAggregator contract.
+var OracleAggregatorMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"getTokenOracleDecimals\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"_tokenOracleDecimals\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"getTokenPrice\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"tokenPriceUnadjusted\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// OracleAggregatorABI is the input ABI used to generate the binding from.
+// Deprecated: Use OracleAggregatorMetaData.ABI instead.
+var OracleAggregatorABI = OracleAggregatorMetaData.ABI
+
+// OracleAggregator is an auto generated Go binding around an Ethereum contract.
+type OracleAggregator struct {
+	OracleAggregatorCaller     // Read-only binding to the contract
+	OracleAggregatorTransactor // Write-only binding to the contract
+	OracleAggregatorFilterer   // Log filterer for contract events
+}
+
+// OracleAggregatorCaller is an auto generated read-only Go binding around an Ethereum contract.
+type OracleAggregatorCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OracleAggregatorTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type OracleAggregatorTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OracleAggregatorFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type OracleAggregatorFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// OracleAggregatorSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type OracleAggregatorSession struct {
+	Contract     *OracleAggregator // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// OracleAggregatorCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type OracleAggregatorCallerSession struct {
+	Contract *OracleAggregatorCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts           // Call options to use throughout this session
+}
+
+// OracleAggregatorTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type OracleAggregatorTransactorSession struct {
+	Contract     *OracleAggregatorTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts           // Transaction auth options to use throughout this session
+}
+
+// OracleAggregatorRaw is an auto generated low-level Go binding around an Ethereum contract.
+type OracleAggregatorRaw struct {
+	Contract *OracleAggregator // Generic contract binding to access the raw methods on
+}
+
+// OracleAggregatorCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type OracleAggregatorCallerRaw struct {
+	Contract *OracleAggregatorCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// OracleAggregatorTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type OracleAggregatorTransactorRaw struct {
+	Contract *OracleAggregatorTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewOracleAggregator creates a new instance of OracleAggregator, bound to a specific deployed contract.
+func NewOracleAggregator(address common.Address, backend bind.ContractBackend) (*OracleAggregator, error) {
+	contract, err := bindOracleAggregator(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleAggregator{OracleAggregatorCaller: OracleAggregatorCaller{contract: contract}, OracleAggregatorTransactor: OracleAggregatorTransactor{contract: contract}, OracleAggregatorFilterer: OracleAggregatorFilterer{contract: contract}}, nil
+}
+
+// NewOracleAggregatorCaller creates a new read-only instance of OracleAggregator, bound to a specific deployed contract.
+func NewOracleAggregatorCaller(address common.Address, caller bind.ContractCaller) (*OracleAggregatorCaller, error) {
+	contract, err := bindOracleAggregator(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleAggregatorCaller{contract: contract}, nil
+}
+
+// NewOracleAggregatorTransactor creates a new write-only instance of OracleAggregator, bound to a specific deployed contract.
+func NewOracleAggregatorTransactor(address common.Address, transactor bind.ContractTransactor) (*OracleAggregatorTransactor, error) {
+	contract, err := bindOracleAggregator(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleAggregatorTransactor{contract: contract}, nil
+}
+
+// NewOracleAggregatorFilterer creates a new log filterer instance of OracleAggregator, bound to a specific deployed contract.
+func NewOracleAggregatorFilterer(address common.Address, filterer bind.ContractFilterer) (*OracleAggregatorFilterer, error) {
+	contract, err := bindOracleAggregator(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &OracleAggregatorFilterer{contract: contract}, nil
+}
+
+// bindOracleAggregator binds a generic wrapper to an already deployed contract.
+func bindOracleAggregator(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(OracleAggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_OracleAggregator *OracleAggregatorRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _OracleAggregator.Contract.OracleAggregatorCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_OracleAggregator *OracleAggregatorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _OracleAggregator.Contract.OracleAggregatorTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_OracleAggregator *OracleAggregatorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _OracleAggregator.Contract.OracleAggregatorTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_OracleAggregator *OracleAggregatorCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _OracleAggregator.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_OracleAggregator *OracleAggregatorTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _OracleAggregator.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_OracleAggregator *OracleAggregatorTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _OracleAggregator.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetTokenOracleDecimals is a free data retrieval call binding the contract method 0x0c9534f3.
+//
+// Solidity: function getTokenOracleDecimals(address token) view returns(uint8 _tokenOracleDecimals)
+func (_OracleAggregator *OracleAggregatorCaller) GetTokenOracleDecimals(opts *bind.CallOpts, token common.Address) (uint8, error) {
+	var out []interface{}
+	err := _OracleAggregator.contract.Call(opts, &out, "getTokenOracleDecimals", token)
+
+	if err != nil {
+		return *new(uint8), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	return out0, err
+
+}
+
+// GetTokenOracleDecimals is a free data retrieval call binding the contract method 0x0c9534f3.
+//
+// Solidity: function getTokenOracleDecimals(address token) view returns(uint8 _tokenOracleDecimals)
+func (_OracleAggregator *OracleAggregatorSession) GetTokenOracleDecimals(token common.Address) (uint8, error) {
+	return _OracleAggregator.Contract.GetTokenOracleDecimals(&_OracleAggregator.CallOpts, token)
+}
+
+// GetTokenOracleDecimals is a free data retrieval call binding the contract method 0x0c9534f3.
+//
+// Solidity: function getTokenOracleDecimals(address token) view returns(uint8 _tokenOracleDecimals)
+func (_OracleAggregator *OracleAggregatorCallerSession) GetTokenOracleDecimals(token common.Address) (uint8, error) {
+	return _OracleAggregator.Contract.GetTokenOracleDecimals(&_OracleAggregator.CallOpts, token)
+}
+
+// GetTokenPrice is a free data retrieval call binding the contract method 0xd02641a0.
+//
+// Solidity: function getTokenPrice(address token) view returns(uint256 tokenPriceUnadjusted)
+func (_OracleAggregator *OracleAggregatorCaller) GetTokenPrice(opts *bind.CallOpts, token common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _OracleAggregator.contract.Call(opts, &out, "getTokenPrice", token)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// GetTokenPrice is a free data retrieval call binding the contract method 0xd02641a0.
+//
+// Solidity: function getTokenPrice(address token) view returns(uint256 tokenPriceUnadjusted)
+func (_OracleAggregator *OracleAggregatorSession) GetTokenPrice(token common.Address) (*big.Int, error) {
+	return _OracleAggregator.Contract.GetTokenPrice(&_OracleAggregator.CallOpts, token)
+}
+
+// GetTokenPrice is a free data retrieval call binding the contract method 0xd02641a0.
+//
+// Solidity: function getTokenPrice(address token) view returns(uint256 tokenPriceUnadjusted)
+func (_OracleAggregator *OracleAggregatorCallerSession) GetTokenPrice(token common.Address) (*big.Int, error) {
+	return _OracleAggregator.Contract.GetTokenPrice(&_OracleAggregator.CallOpts, token)
+}
@@ -0,0 +1,242 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package recipient
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// RecipientMetaData contains all meta data concerning the Recipient contract.
+var RecipientMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"forwarder\",\"type\":\"address\"}],\"name\":\"isTrustedForwarder\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"versionRecipient\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// RecipientABI is the input ABI used to generate the binding from.
+// Deprecated: Use RecipientMetaData.ABI instead.
+var RecipientABI = RecipientMetaData.ABI
+
+// Recipient is an auto generated Go binding around an Ethereum contract.
+type Recipient struct {
+	RecipientCaller     // Read-only binding to the contract
+	RecipientTransactor // Write-only binding to the contract
+	RecipientFilterer   // Log filterer for contract events
+}
+
+// RecipientCaller is an auto generated read-only Go binding around an Ethereum contract.
+type RecipientCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RecipientTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type RecipientTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RecipientFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type RecipientFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// RecipientSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type RecipientSession struct {
+	Contract     *Recipient        // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// RecipientCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type RecipientCallerSession struct {
+	Contract *RecipientCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts    // Call options to use throughout this session
+}
+
+// RecipientTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type RecipientTransactorSession struct {
+	Contract     *RecipientTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
+}
+
+// RecipientRaw is an auto generated low-level Go binding around an Ethereum contract.
+type RecipientRaw struct {
+	Contract *Recipient // Generic contract binding to access the raw methods on
+}
+
+// RecipientCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type RecipientCallerRaw struct {
+	Contract *RecipientCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// RecipientTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type RecipientTransactorRaw struct {
+	Contract *RecipientTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewRecipient creates a new instance of Recipient, bound to a specific deployed contract.
+func NewRecipient(address common.Address, backend bind.ContractBackend) (*Recipient, error) {
+	contract, err := bindRecipient(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Recipient{RecipientCaller: RecipientCaller{contract: contract}, RecipientTransactor: RecipientTransactor{contract: contract}, RecipientFilterer: RecipientFilterer{contract: contract}}, nil
+}
+
+// NewRecipientCaller creates a new read-only instance of Recipient, bound to a specific deployed contract.
+func NewRecipientCaller(address common.Address, caller bind.ContractCaller) (*RecipientCaller, error) {
+	contract, err := bindRecipient(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RecipientCaller{contract: contract}, nil
+}
+
+// NewRecipientTransactor creates a new write-only instance of Recipient, bound to a specific deployed contract.
+func NewRecipientTransactor(address common.Address, transactor bind.ContractTransactor) (*RecipientTransactor, error) {
+	contract, err := bindRecipient(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RecipientTransactor{contract: contract}, nil
+}
+
+// NewRecipientFilterer creates a new log filterer instance of Recipient, bound to a specific deployed contract.
+func NewRecipientFilterer(address common.Address, filterer bind.ContractFilterer) (*RecipientFilterer, error) {
+	contract, err := bindRecipient(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &RecipientFilterer{contract: contract}, nil
+}
+
+// bindRecipient binds a generic wrapper to an already deployed contract.
+func bindRecipient(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(RecipientABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Recipient *RecipientRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Recipient.Contract.RecipientCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Recipient *RecipientRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Recipient.Contract.RecipientTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Recipient *RecipientRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Recipient.Contract.RecipientTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Recipient *RecipientCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Recipient.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Recipient *RecipientTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Recipient.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Recipient *RecipientTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Recipient.Contract.contract.Transact(opts, method, params...)
+}
+
+// IsTrustedForwarder is a free data retrieval call binding the contract method 0x572b6c05.
+//
+// Solidity: function isTrustedForwarder(address forwarder) view returns(bool)
+func (_Recipient *RecipientCaller) IsTrustedForwarder(opts *bind.CallOpts, forwarder common.Address) (bool, error) {
+	var out []interface{}
+	err := _Recipient.contract.Call(opts, &out, "isTrustedForwarder", forwarder)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// IsTrustedForwarder is a free data retrieval call binding the contract method 0x572b6c05.
+//
+// Solidity: function isTrustedForwarder(address forwarder) view returns(bool)
+func (_Recipient *RecipientSession) IsTrustedForwarder(forwarder common.Address) (bool, error) {
+	return _Recipient.Contract.IsTrustedForwarder(&_Recipient.CallOpts, forwarder)
+}
+
+// IsTrustedForwarder is a free data retrieval call binding the contract method 0x572b6c05.
+//
+// Solidity: function isTrustedForwarder(address forwarder) view returns(bool)
+func (_Recipient *RecipientCallerSession) IsTrustedForwarder(forwarder common.Address) (bool, error) {
+	return _Recipient.Contract.IsTrustedForwarder(&_Recipient.CallOpts, forwarder)
+}
+
+// VersionRecipient is a free data retrieval call binding the contract method 0x486ff0cd.
+//
+// Solidity: function versionRecipient() view returns(string)
+func (_Recipient *RecipientCaller) VersionRecipient(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := _Recipient.contract.Call(opts, &out, "versionRecipient")
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
+}
+
+// VersionRecipient is a free data retrieval call binding the contract method 0x486ff0cd.
+//
+// Solidity: function versionRecipient() view returns(string)
+func (_Recipient *RecipientSession) VersionRecipient() (string, error) {
+	return _Recipient.Contract.VersionRecipient(&_Recipient.CallOpts)
+}
+
+// VersionRecipient is a free data retrieval call binding the contract method 0x486ff0cd.
+//
+// Solidity: function versionRecipient() view returns(string)
+func (_Recipient *RecipientCallerSession) VersionRecipient() (string, error) {
+	return _Recipient.Contract.VersionRecipient(&_Recipient.CallOpts)
+}
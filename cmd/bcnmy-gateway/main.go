@@ -0,0 +1,147 @@
+// Command bcnmy-gateway serves the metax/server HTTP gateway.
+//
+//	bcnmy-gateway -rpc https://rpc-mumbai.maticvigil.com -api-key $KEY \
+//		-dapp 0xDapp=./Dapp.json -allow 0xDapp:transfer -origins https://app.example
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+	"github.com/oblzh/bcnmy-go/metax/server"
+)
+
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var dapps, allows listFlag
+	addr := flag.String("addr", ":8080", "listen address")
+	rpc := flag.String("rpc", "", "chain HTTP RPC endpoint")
+	apiKey := flag.String("api-key", os.Getenv("BCNMY_API_KEY"), "Biconomy DAPP API key, or BCNMY_API_KEY")
+	origins := flag.String("origins", "", "comma separated CORS origins, * for any")
+	confirmations := flag.Uint64("confirmations", 1, "confirmations a submission is waited for")
+	timeout := flag.Duration("timeout", 10*time.Second, "Biconomy API timeout")
+	verbose := flag.Bool("v", false, "debug logging")
+	flag.Var(&dapps, "dapp", "address=abi.json of a served DAPP, repeatable")
+	flag.Var(&allows, "allow", "address:method allowed on a DAPP, repeatable; every method of a DAPP without one")
+	flag.Parse()
+
+	logger := logrus.WithField("metax", "gateway")
+	if *verbose {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+	if *rpc == "" || *apiKey == "" || len(dapps) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	b, err := metax.NewBcnmy(*rpc, *apiKey, *timeout)
+	if err != nil {
+		logger.WithError(err).Fatal("NewBcnmy failed")
+	}
+	policy := make(server.Policy)
+	for _, dapp := range dapps {
+		address, abiPath, err := splitFlag(dapp, "=")
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid -dapp")
+		}
+		jsonABI, err := os.ReadFile(abiPath)
+		if err != nil {
+			logger.WithError(err).Fatalf("Read ABI %s failed", abiPath)
+		}
+		if _, err := b.RegisterDapp(string(jsonABI), address); err != nil {
+			logger.WithError(err).Fatalf("Register DAPP %s failed", address.Hex())
+		}
+		policy[address] = nil
+	}
+	for _, allow := range allows {
+		address, method, err := splitFlag(allow, ":")
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid -allow")
+		}
+		if _, ok := policy[address]; !ok {
+			logger.Fatalf("-allow DAPP %s has no -dapp", address.Hex())
+		}
+		policy[address] = append(policy[address], method)
+	}
+
+	config := server.Config{
+		Policy:        policy,
+		Confirmations: *confirmations,
+		Logger:        logger,
+	}
+	if *origins != "" {
+		config.AllowedOrigins = strings.Split(*origins, ",")
+	}
+	gateway, err := server.New(b, config)
+	if err != nil {
+		logger.WithError(err).Fatal("New server failed")
+	}
+	if err := serve(*addr, gateway, logger); err != nil {
+		logger.WithError(err).Fatal("Serve failed")
+	}
+}
+
+// serve runs the gateway until SIGINT or SIGTERM, then drains the requests
+// in progress and stops the background waits.
+func serve(addr string, gateway *server.Server, logger *logrus.Entry) error {
+	defer gateway.Close()
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           gateway,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		// a submission waits for Biconomy to relay it
+		WriteTimeout: time.Minute,
+		IdleTimeout:  2 * time.Minute,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+	logger.Infof("Listening on %s", addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func splitFlag(value string, sep string) (common.Address, string, error) {
+	address, rest, ok := strings.Cut(value, sep)
+	if !ok || !common.IsHexAddress(address) || rest == "" {
+		return common.Address{}, "", fmt.Errorf("%q is not address%svalue", value, sep)
+	}
+	return common.HexToAddress(address), rest, nil
+}
@@ -0,0 +1,239 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultAPIIDTTL is how long a loaded apiId table is trusted.
+var DefaultAPIIDTTL = 10 * time.Minute
+
+// apiIDMissInterval throttles the reloads caused by unknown methods.
+const apiIDMissInterval = 2 * time.Second
+
+// apiIDRetryInterval is how long the error of a failed load is returned
+// instead of loading again, so a meta API outage does not stall every call.
+const apiIDRetryInterval = 10 * time.Second
+
+// apiIDLoadTimeout bounds a shared load, which does not end with the ctx of
+// the caller starting it.
+const apiIDLoadTimeout = time.Minute
+
+// apiIDFlight is a meta API load in progress, shared by every caller that
+// needs the table meanwhile.
+type apiIDFlight struct {
+	done chan struct{}
+	err  error
+}
+
+func apiIDKey(address common.Address, method string) string {
+	return fmt.Sprintf("%s-%s", address.Hex(), method)
+}
+
+func (b *Bcnmy) setAPIIDLocked(listAPI []MetaAPIInfo) {
+	for _, info := range listAPI {
+		// filter non contractAddress
+		if common.IsHexAddress(info.ContractAddress) {
+			b.putAPIIDLocked(info)
+		}
+	}
+}
+
+// putAPIIDLocked stores info under its method, and its selector when the
+// dashboard method is a signature.
+func (b *Bcnmy) putAPIIDLocked(info MetaAPIInfo) {
+	address := common.HexToAddress(info.ContractAddress)
+	for _, key := range apiIDMethodKeys(info.Method) {
+		b.apiID[apiIDKey(address, key)] = info
+	}
+}
+
+func (b *Bcnmy) apiIDExpiredLocked() bool {
+	return b.apiIDTTL > 0 && time.Since(b.apiIDLoadedAt) > b.apiIDTTL
+}
+
+// refreshAPIID reloads the apiId table from the meta API. Concurrent callers
+// wait for the load already running instead of starting another; a caller
+// giving up on its ctx does not fail the load for the others.
+func (b *Bcnmy) refreshAPIID(ctx context.Context) error {
+	b.mu.Lock()
+	flight := b.apiIDFlight
+	if flight == nil {
+		flight = &apiIDFlight{done: make(chan struct{})}
+		b.apiIDFlight = flight
+		go b.loadAPIID(flight)
+	}
+	b.mu.Unlock()
+	select {
+	case <-flight.done:
+		return flight.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refreshAPIIDBackoff is refreshAPIID, returning the error of the last load
+// instead while it failed less than apiIDRetryInterval ago.
+func (b *Bcnmy) refreshAPIIDBackoff(ctx context.Context) error {
+	b.mu.RLock()
+	err, failedAt := b.apiIDErr, b.apiIDFailedAt
+	b.mu.RUnlock()
+	if err != nil && time.Since(failedAt) < apiIDRetryInterval {
+		return err
+	}
+	return b.refreshAPIID(ctx)
+}
+
+// loadAPIID runs the load of flight on the client's ctx.
+func (b *Bcnmy) loadAPIID(flight *apiIDFlight) {
+	ctx, cancel := context.WithTimeout(b.ctx, apiIDLoadTimeout)
+	defer cancel()
+	resp, err := b.GetMetaAPI(ctx)
+
+	b.mu.Lock()
+	if err == nil {
+		b.apiID = make(map[string]MetaAPIInfo)
+		b.setAPIIDLocked(resp.ListAPI)
+		b.apiIDLoaded = true
+		b.apiIDLoadedAt = time.Now()
+		b.apiIDErr = nil
+	} else {
+		b.logger.WithError(err).Error("Load apiId table failed")
+		b.apiIDErr = err
+		b.apiIDFailedAt = time.Now()
+	}
+	b.apiIDFlight = nil
+	b.mu.Unlock()
+
+	flight.err = err
+	close(flight.done)
+}
+
+// lookupAPIID returns the entry of the first of methods found.
+func (b *Bcnmy) lookupAPIID(address common.Address, methods ...string) (MetaAPIInfo, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, method := range methods {
+		if info, ok := b.apiID[apiIDKey(address, normalizeMethod(method))]; ok {
+			return info, true
+		}
+	}
+	return MetaAPIInfo{}, false
+}
+
+// resolveAPIID looks up the first of methods found, reloading the table once
+// on a miss so that methods added on the dashboard meanwhile are found.
+func (b *Bcnmy) resolveAPIID(ctx context.Context, address common.Address, methods ...string) (MetaAPIInfo, error) {
+	if info, ok := b.lookupAPIID(address, methods...); ok {
+		return info, nil
+	}
+	b.mu.RLock()
+	recent := time.Since(b.apiIDLoadedAt) < apiIDMissInterval
+	b.mu.RUnlock()
+	if !recent {
+		if err := b.refreshAPIIDBackoff(ctx); err != nil {
+			b.logger.WithError(err).Warn("Refresh apiId table on miss failed")
+		}
+		if info, ok := b.lookupAPIID(address, methods...); ok {
+			return info, nil
+		}
+	}
+	err := &ApiIdNotFoundError{Address: address, Method: methods[0]}
+	b.logger.Error(err.Error())
+	return MetaAPIInfo{}, err
+}
+
+// MethodInfo returns the meta API entry of method on the DAPP at address,
+// with its apiId, limit configuration and MetaTxLimitStatus. method is the
+// name, signature or selector the entry was registered with.
+func (b *Bcnmy) MethodInfo(ctx context.Context, address common.Address, method string) (*MetaAPIInfo, error) {
+	if err := b.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	info, err := b.resolveAPIID(ctx, address, method)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// MethodInfo resolves method on the DAPP ABI like Method does and returns
+// its meta API entry. A name the ABI does not know is looked up as is.
+func (d *DappHandle) MethodInfo(ctx context.Context, method string) (*MetaAPIInfo, error) {
+	if err := d.b.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	info, err := d.resolveAPIIDOf(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// resolveAPIIDOf looks method up without call arguments, by the keys of the
+// ABI method it names or, when the ABI cannot tell, as written.
+func (d *DappHandle) resolveAPIIDOf(ctx context.Context, method string) (MetaAPIInfo, error) {
+	if m, err := d.resolveMethod(method, nil, false); err == nil {
+		return d.resolveMethodAPIID(ctx, m)
+	}
+	return d.b.resolveAPIID(ctx, d.address, method)
+}
+
+// MetaAPIs lists the cached meta API entries.
+func (b *Bcnmy) MetaAPIs() []MetaAPIInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	list := make([]MetaAPIInfo, 0, len(b.apiID))
+	for _, info := range b.apiID {
+		list = append(list, info)
+	}
+	return list
+}
+
+// addMethodAPIIDs records the apiIds returned by AddMethod.
+func (b *Bcnmy) addMethodAPIIDs(data *AddMethodRequest, resp *AddMethodResponse) {
+	if !common.IsHexAddress(data.ContractAddress) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, api := range resp.ApiIds {
+		if api.ApiId == "" {
+			continue
+		}
+		b.putAPIIDLocked(MetaAPIInfo{
+			ContractAddress: data.ContractAddress,
+			ID:              api.ApiId,
+			Name:            api.Name,
+			Method:          api.Method,
+			MethodType:      data.MethodType,
+			APIType:         data.ApiType,
+		})
+	}
+}
+
+// deleteAPIIDs drops the methods of address, all of them when method is empty.
+func (b *Bcnmy) deleteAPIIDs(contractAddress string, method string) {
+	if !common.IsHexAddress(contractAddress) {
+		return
+	}
+	address := common.HexToAddress(contractAddress)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if method != "" {
+		for _, key := range apiIDMethodKeys(method) {
+			delete(b.apiID, apiIDKey(address, key))
+		}
+		return
+	}
+	prefix := apiIDKey(address, "")
+	for key := range b.apiID {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.apiID, key)
+		}
+	}
+}
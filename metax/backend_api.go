@@ -1,12 +1,14 @@
 package metax
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"math/big"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 /*
@@ -110,23 +112,27 @@ type LoginResponse struct {
 }
 
 func (b *Bcnmy) BackendLogin() (*LoginResponse, error) {
+	return b.BackendLoginContext(b.ctx)
+}
+
+func (b *Bcnmy) BackendLoginContext(ctx context.Context) (*LoginResponse, error) {
 	body := url.Values{
 		"email":    {b.email},
 		"password": {b.password},
 	}
-	loginResp, err := b.backendHttpClient.PostForm(BackendLoginURL, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.BackendURL(BackendLoginPath), strings.NewReader(body.Encode()))
 	if err != nil {
-		b.logger.WithError(err).Error("BackendLogin error")
+		b.logger.WithError(err).Error("BackendLogin NewRequest failed")
 		return nil, err
 	}
-	defer loginResp.Body.Close()
-	replyData, err := io.ReadAll(loginResp.Body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	statusCode, replyData, err := b.doHttpx(b.backendHttpClient, req)
 	if err != nil {
-		b.logger.WithError(err).Error("BackendLogin Read body error")
+		b.logger.WithError(err).Error("BackendLogin error")
 		return nil, err
 	}
-	if loginResp.StatusCode != 200 {
-		err = fmt.Errorf("BackendLogin got %v", loginResp.Status)
+	if statusCode != 200 {
+		err = &HTTPStatusError{StatusCode: statusCode, Body: replyData}
 		b.logger.WithError(err).Error("BackendLogin status error")
 		return nil, err
 	}
@@ -139,12 +145,16 @@ func (b *Bcnmy) BackendLogin() (*LoginResponse, error) {
 }
 
 func (b *Bcnmy) BackendDappList() (*DappResponse, error) {
+	return b.BackendDappListContext(b.ctx)
+}
+
+func (b *Bcnmy) BackendDappListContext(ctx context.Context) (*DappResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(errorCh)
 	defer close(bodyCh)
 
-	req, err := http.NewRequest(http.MethodGet, BackendDappURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoints.BackendURL(BackendDappPath), nil)
 	if err != nil {
 		b.logger.WithError(err).Error("BackendDappList NewRequest failed")
 		return nil, err
@@ -166,20 +176,28 @@ func (b *Bcnmy) BackendDappList() (*DappResponse, error) {
 }
 
 func (b *Bcnmy) GetBackendDapps() (*DappResponse, error) {
-	resp, err := b.BackendDappList()
-	if err != nil && err.Error() == "401" {
-		loginResp, loginErr := b.BackendLogin()
+	return b.GetBackendDappsContext(b.ctx)
+}
+
+func (b *Bcnmy) GetBackendDappsContext(ctx context.Context) (*DappResponse, error) {
+	resp, err := b.BackendDappListContext(ctx)
+	if errors.Is(err, ErrUnauthorized) {
+		loginResp, loginErr := b.BackendLoginContext(ctx)
 		if loginErr != nil {
 			b.logger.WithError(loginErr).Errorf("GetBackendDapps login error %+v", loginResp)
 			return nil, loginErr
 		}
-		resp, err = b.BackendDappList()
+		resp, err = b.BackendDappListContext(ctx)
 	}
 	return resp, err
 }
 
 func (b *Bcnmy) GetGasTankEffectiveBalance() (*big.Int, error) {
-	resp, err := b.GetBackendDapps()
+	return b.GetGasTankEffectiveBalanceContext(b.ctx)
+}
+
+func (b *Bcnmy) GetGasTankEffectiveBalanceContext(ctx context.Context) (*big.Int, error) {
+	resp, err := b.GetBackendDappsContext(ctx)
 	if err != nil {
 		b.logger.Errorf("GetGasTankEffectiveBalance failed")
 		return nil, err
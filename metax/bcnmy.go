@@ -6,10 +6,10 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/cookiejar"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/sirupsen/logrus"
@@ -21,29 +21,53 @@ type Bcnmy struct {
 	ctx    context.Context
 	logger *logrus.Entry
 
-	ethClient    *ethclient.Client
+	// guards lazy chain discovery and the apiID table
+	mu sync.RWMutex
+
+	httpRpc      string
+	ethClient    bind.ContractBackend
 	sleepTimeSec time.Duration
+	pollInterval time.Duration
 	httpClient   *http.Client
+	retryPolicy  RetryPolicy
+	limiter      *rateLimiter
+	endpoints    Endpoints
 
-	// DAPP abi and address
-	abi     abi.ABI
-	address common.Address
+	// registered DAPPs and the one WithDapp selected, guarded by mu
+	dapps map[common.Address]*DappHandle
+	dapp  *DappHandle
 
 	authToken string
 	apiKey    string
-	/// method apiID
-	apiID map[string]struct {
-		ID              string
-		ContractAddress string
-	}
+	/// method apiID, see apiid.go
+	apiID         map[string]MetaAPIInfo
+	apiIDLoaded   bool
+	apiIDLoadedAt time.Time
+	apiIDTTL      time.Duration
+	apiIDFlight   *apiIDFlight
+	apiIDErr      error
+	apiIDFailedAt time.Time
 
-	batchId *big.Int
-	chainId *big.Int
+	nonces    *nonceManager
+	nonceHold time.Duration
+	chainId   *big.Int
 
 	trustedForwarder struct {
 		Address  common.Address
 		Contract *forwarder.Forwarder
 	}
+	// TxGas and deadline of signed forward requests, see gas.go and deadline.go
+	gasStrategy    GasStrategy
+	deadlinePolicy DeadlinePolicy
+	// ERC20 forwarder fee proxy, see erc20_fee.go
+	feeProxy common.Address
+	// relayers in priority order, see relayer.go and self_relay.go
+	relayers    []Relayer
+	relayPolicy FallbackPolicy
+	selfRelayer *selfRelayer
+	// DAPPs known to trust the forwarder, see erc2771.go
+	checkTrusted bool
+	trustedBy    map[common.Address]bool
 
 	// backend config
 	email             string
@@ -52,78 +76,178 @@ type Bcnmy struct {
 }
 
 func NewBcnmy(httpRpc string, apiKey string, timeout time.Duration) (*Bcnmy, error) {
-	var err error
-	bcnmy := &Bcnmy{
-		ctx:    context.Background(),
-		logger: logrus.WithField("metax", "bcnmy"),
-		apiKey: apiKey,
-		apiID: make(map[string]struct {
-			ID              string
-			ContractAddress string
-		}),
-		batchId:      big.NewInt(0),
-		httpClient:   &http.Client{Timeout: timeout},
-		sleepTimeSec: time.Duration(5),
-	}
-	bcnmy.ethClient, err = ethclient.DialContext(bcnmy.ctx, httpRpc)
+	return NewBcnmyWithEndpoints(httpRpc, apiKey, timeout, DefaultEndpoints)
+}
+
+// NewBcnmyWithProfile builds a Bcnmy against a named entry of EndpointProfiles.
+func NewBcnmyWithProfile(httpRpc string, apiKey string, timeout time.Duration, profile string) (*Bcnmy, error) {
+	endpoints, err := LookupEndpoints(profile)
 	if err != nil {
-		bcnmy.logger.WithError(err).Error("DialContext ethclient failed")
+		logrus.WithField("metax", "bcnmy").WithError(err).Error("LookupEndpoints failed")
 		return nil, err
 	}
-	bcnmy.chainId, err = bcnmy.ethClient.ChainID(bcnmy.ctx)
+	return NewBcnmyWithEndpoints(httpRpc, apiKey, timeout, endpoints)
+}
+
+// NewBcnmyWithEndpoints dials the RPC and loads the apiId table eagerly,
+// failing construction when either is unreachable. Use New to defer that.
+func NewBcnmyWithEndpoints(httpRpc string, apiKey string, timeout time.Duration, endpoints Endpoints) (*Bcnmy, error) {
+	bcnmy, err := New(apiKey, WithRPC(httpRpc), WithHTTPTimeout(timeout), WithEndpoints(endpoints))
 	if err != nil {
-		bcnmy.logger.WithError(err).Error("ethClient getchainId failed")
 		return nil, err
 	}
+	if err = bcnmy.Refresh(bcnmy.ctx); err != nil {
+		return nil, err
+	}
+	return bcnmy, nil
+}
 
-	forwarderAddress, ok := ForwarderAddressMap[bcnmy.chainId.String()]
-	if !ok {
-		err = fmt.Errorf("Chain ID not supported: %v", bcnmy.chainId)
+// New builds a Bcnmy without any network access. The RPC is dialed, the
+// chain ID fetched and the apiId table loaded on first use, unless they were
+// supplied through options. Call Refresh to discover them explicitly.
+func New(apiKey string, opts ...Option) (*Bcnmy, error) {
+	bcnmy := &Bcnmy{
+		ctx:          context.Background(),
+		logger:       logrus.WithField("metax", "bcnmy"),
+		apiKey:       apiKey,
+		apiID:        make(map[string]MetaAPIInfo),
+		apiIDTTL:     DefaultAPIIDTTL,
+		dapps:        make(map[common.Address]*DappHandle),
+		trustedBy:    make(map[common.Address]bool),
+		nonces:       newNonceManager(SequentialNonces, 1),
+		nonceHold:    DefaultNonceHold,
+		httpClient:   &http.Client{},
+		retryPolicy:  DefaultRetryPolicy,
+		gasStrategy:  DefaultForwarderGasStrategy,
+		limiter:      newRateLimiter(RateLimits{}),
+		sleepTimeSec: time.Duration(5),
+		pollInterval: DefaultPollInterval,
+		endpoints:    DefaultEndpoints,
+	}
+	for _, opt := range opts {
+		if err := opt(bcnmy); err != nil {
+			bcnmy.logger.WithError(err).Error("Apply option failed")
+			return nil, err
+		}
+	}
+	if err := bcnmy.endpoints.Validate(); err != nil {
+		bcnmy.logger.WithError(err).Error("Endpoints validate failed")
+		return nil, err
+	}
+	if bcnmy.ethClient == nil && bcnmy.httpRpc == "" {
+		err := fmt.Errorf("Either an RPC url or a contract backend is required")
 		bcnmy.logger.Error(err.Error())
 		return nil, err
 	}
+	return bcnmy, nil
+}
 
-	forwarderContract, err := forwarder.NewForwarder(forwarderAddress, bcnmy.ethClient)
-	if err != nil {
-		bcnmy.logger.WithError(err).Error("Load Forwarder Contract failed")
-		return nil, err
+// Refresh performs remote discovery: it connects the chain backend when not
+// yet done, reloads the method apiId table from the meta API and forgets
+// which DAPPs were found to trust the forwarder.
+func (b *Bcnmy) Refresh(ctx context.Context) error {
+	if err := b.ensureChain(ctx); err != nil {
+		return err
 	}
+	b.mu.Lock()
+	b.trustedBy = make(map[common.Address]bool)
+	b.mu.Unlock()
+	return b.refreshAPIID(ctx)
+}
 
-	bcnmy.trustedForwarder = struct {
-		Address  common.Address
-		Contract *forwarder.Forwarder
-	}{
-		Address:  forwarderAddress,
-		Contract: forwarderContract,
+// ensureReady lazily performs whatever discovery was not preloaded and
+// refreshes the apiId table once its TTL expired.
+func (b *Bcnmy) ensureReady(ctx context.Context) error {
+	if err := b.ensureChain(ctx); err != nil {
+		return err
 	}
-	resp, err := bcnmy.GetMetaAPI(bcnmy.ctx)
-	if err != nil {
-		bcnmy.logger.WithError(err).Error(err.Error())
-		return nil, err
+	b.mu.RLock()
+	loaded, expired := b.apiIDLoaded, b.apiIDExpiredLocked()
+	b.mu.RUnlock()
+	if !loaded {
+		return b.refreshAPIIDBackoff(ctx)
 	}
-	for _, info := range resp.ListAPI {
-		// filter non contractAddress
-		if common.IsHexAddress(info.ContractAddress) {
-			bcnmy.apiID[fmt.Sprintf("%s-%s", common.HexToAddress(info.ContractAddress).Hex(), info.Method)] = struct {
-				ID              string
-				ContractAddress string
-			}{
-				ID:              info.ID,
-				ContractAddress: info.ContractAddress,
-			}
+	if expired {
+		// a stale table is still better than failing the call
+		if err := b.refreshAPIIDBackoff(ctx); err != nil {
+			b.logger.WithError(err).Warn("Refresh expired apiId table failed")
 		}
 	}
-	return bcnmy, nil
+	return nil
 }
 
-func (b *Bcnmy) WithDapp(jsonABI string, dappAddress common.Address) (*Bcnmy, error) {
+// ensureChain lazily connects the chain backend and the trusted forwarder.
+func (b *Bcnmy) ensureChain(ctx context.Context) error {
+	b.mu.RLock()
+	ready := b.trustedForwarder.Contract != nil
+	b.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.initChainLocked(ctx)
+}
+
+func (b *Bcnmy) initChainLocked(ctx context.Context) error {
 	var err error
-	b.address = dappAddress
-	b.abi, err = abi.JSON(strings.NewReader(jsonABI))
+	if b.trustedForwarder.Contract != nil {
+		return nil
+	}
+	if b.ethClient == nil {
+		b.ethClient, err = ethclient.DialContext(ctx, b.httpRpc)
+		if err != nil {
+			b.logger.WithError(err).Error("DialContext ethclient failed")
+			return err
+		}
+	}
+	if b.chainId == nil {
+		reader, ok := b.ethClient.(chainIDReader)
+		if !ok {
+			err = fmt.Errorf("Contract backend cannot report chain ID, use WithChainID")
+			b.logger.Error(err.Error())
+			return err
+		}
+		b.chainId, err = reader.ChainID(ctx)
+		if err != nil {
+			b.logger.WithError(err).Error("ethClient getchainId failed")
+			return err
+		}
+	}
+
+	forwarderAddress := b.trustedForwarder.Address
+	if forwarderAddress == (common.Address{}) {
+		var ok bool
+		forwarderAddress, ok = ForwarderAddressMap[b.chainId.String()]
+		if !ok {
+			err = &ChainNotSupportedError{ChainID: b.chainId}
+			b.logger.Error(err.Error())
+			return err
+		}
+	}
+
+	forwarderContract, err := forwarder.NewForwarder(forwarderAddress, b.ethClient)
+	if err != nil {
+		b.logger.WithError(err).Error("Load Forwarder Contract failed")
+		return err
+	}
+	b.trustedForwarder.Address = forwarderAddress
+	b.trustedForwarder.Contract = forwarderContract
+	return nil
+}
+
+// WithDapp registers the DAPP and makes it the target of the Bcnmy level
+// RawTransact, EnhanceTransact, CheckLimits and Pack. Serving several DAPPs
+// concurrently use RegisterDapp and the returned DappHandle instead.
+func (b *Bcnmy) WithDapp(jsonABI string, dappAddress common.Address) (*Bcnmy, error) {
+	dapp, err := b.RegisterDapp(jsonABI, dappAddress)
 	if err != nil {
-		b.logger.WithError(err).Error("jsonABI parse failed")
 		return nil, err
 	}
+	b.mu.Lock()
+	b.dapp = dapp
+	b.mu.Unlock()
 	return b, nil
 }
 
@@ -142,6 +266,22 @@ func (b *Bcnmy) WithSleepTimeSec(sleepTimeSec int64) *Bcnmy {
 	return b
 }
 
+func (b *Bcnmy) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.chainId), nil
+}
+
+// RateLimiterStats returns the wait time metrics of every endpoint group.
+func (b *Bcnmy) RateLimiterStats() map[EndpointGroup]LimiterStats {
+	return b.limiter.stats()
+}
+
+func (b *Bcnmy) Endpoints() Endpoints {
+	return b.endpoints
+}
+
 func (b *Bcnmy) GetAuthorization() string {
 	return fmt.Sprintf("User %s", b.authToken)
 }
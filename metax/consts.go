@@ -6,27 +6,60 @@ import (
 )
 
 const (
-	MetaAPIURL                 = "https://api.biconomy.io/api/v1/meta-api"
-	MetaTxNativeURL            = "https://api.biconomy.io/api/v2/meta-tx/native"
-	CreateDappPublicURL        = "https://api.biconomy.io/api/v1/dapp/public-api/create-dapp"
-	AddContractURL             = "https://api.biconomy.io/api/v1/smart-contract/public-api/addContract"
-	AddMethodURL               = "https://api.biconomy.io/api/v1/meta-api/public-api/addMethod"
-	DeleteContractURL          = "https://api.biconomy.io/api/v1/smart-contract/public-api/deleteContract"
-	DeleteMethodURL            = "https://api.biconomy.io/api/v1/meta-api/public-api/deleteMethod"
-	AddDestinationAddressesURL = "https://api.biconomy.io/api/v1/dapp/whitelist/destination"
-	ProxyContractsURL          = "https://api.biconomy.io/api/v1/dapp/whitelist/proxy-contracts"
-	UniqueUserDataURL          = "https://data.biconomy.io/api/v1/dapp/uniqueUserData"
-	UserLimitURL               = "https://data.biconomy.io/api/v1/dapp/user-limit"
-	GasTankBalanceURL          = "https://data.biconomy.io/api/v1/dapp/gas-tank-balance"
-	CheckLimitURL              = "https://api.biconomy.io/api/v1/dapp/checkLimits"
+	ProductionAPIHost         = "https://api.biconomy.io"
+	ProductionDataHost        = "https://data.biconomy.io"
+	ProductionBackendHost     = "https://dashboard-backend.prod.biconomy.io"
+	ProductionGaslessMetaHost = "https://gasless-meta.prod.biconomy.io"
+)
+
+const (
+	MetaAPIPath                 = "/api/v1/meta-api"
+	MetaTxNativePath            = "/api/v2/meta-tx/native"
+	CreateDappPublicPath        = "/api/v1/dapp/public-api/create-dapp"
+	AddContractPath             = "/api/v1/smart-contract/public-api/addContract"
+	AddMethodPath               = "/api/v1/meta-api/public-api/addMethod"
+	DeleteContractPath          = "/api/v1/smart-contract/public-api/deleteContract"
+	DeleteMethodPath            = "/api/v1/meta-api/public-api/deleteMethod"
+	AddDestinationAddressesPath = "/api/v1/dapp/whitelist/destination"
+	ProxyContractsPath          = "/api/v1/dapp/whitelist/proxy-contracts"
+	UniqueUserDataPath          = "/api/v1/dapp/uniqueUserData"
+	UserLimitPath               = "/api/v1/dapp/user-limit"
+	GasTankBalancePath          = "/api/v1/dapp/gas-tank-balance"
+	CheckLimitPath              = "/api/v1/dapp/checkLimits"
 
 	// backend
-	BackendLoginURL = "https://dashboard-backend.prod.biconomy.io/api/v1/user/login"
-	BackendDappURL  = "https://dashboard-backend.prod.biconomy.io/api/v1/dapp"
+	BackendLoginPath = "/api/v1/user/login"
+	BackendDappPath  = "/api/v1/dapp"
 
 	// v1 sdk
-	MetaTxNativeURLV1        = "https://gasless-meta.prod.biconomy.io/api/v1/native"
-	MetaTransactionStatusURL = "https://gasless-meta.prod.biconomy.io/api/v1/sdk/transaction-status"
+	MetaTxNativePathV1        = "/api/v1/native"
+	MetaTransactionStatusPath = "/api/v1/sdk/transaction-status"
+)
+
+// Production URLs, kept for callers that build requests by hand.
+// Bcnmy itself resolves every URL through its Endpoints.
+const (
+	MetaAPIURL                 = ProductionAPIHost + MetaAPIPath
+	MetaTxNativeURL            = ProductionAPIHost + MetaTxNativePath
+	CreateDappPublicURL        = ProductionAPIHost + CreateDappPublicPath
+	AddContractURL             = ProductionAPIHost + AddContractPath
+	AddMethodURL               = ProductionAPIHost + AddMethodPath
+	DeleteContractURL          = ProductionAPIHost + DeleteContractPath
+	DeleteMethodURL            = ProductionAPIHost + DeleteMethodPath
+	AddDestinationAddressesURL = ProductionAPIHost + AddDestinationAddressesPath
+	ProxyContractsURL          = ProductionAPIHost + ProxyContractsPath
+	UniqueUserDataURL          = ProductionDataHost + UniqueUserDataPath
+	UserLimitURL               = ProductionDataHost + UserLimitPath
+	GasTankBalanceURL          = ProductionDataHost + GasTankBalancePath
+	CheckLimitURL              = ProductionAPIHost + CheckLimitPath
+
+	// backend
+	BackendLoginURL = ProductionBackendHost + BackendLoginPath
+	BackendDappURL  = ProductionBackendHost + BackendDappPath
+
+	// v1 sdk
+	MetaTxNativeURLV1        = ProductionGaslessMetaHost + MetaTxNativePathV1
+	MetaTransactionStatusURL = ProductionGaslessMetaHost + MetaTransactionStatusPath
 )
 
 const (
@@ -34,11 +67,17 @@ const (
 )
 
 const (
-	SignatureEIP712Type = "EIP712_SIGN"
-	EIP712DomainType    = "EIP712Domain"
-	ForwardRequestType  = "ERC20ForwardRequest"
-	ForwardRequestName  = "Biconomy Forwarder"
-	Version             = "1"
+	// TransactionStatusPolls bounds GetTransactionStatus, polls are sleepTimeSec apart
+	TransactionStatusPolls = 5
+)
+
+const (
+	SignatureEIP712Type   = "EIP712_SIGN"
+	SignaturePersonalType = "PERSONAL_SIGN"
+	EIP712DomainType      = "EIP712Domain"
+	ForwardRequestType    = "ERC20ForwardRequest"
+	ForwardRequestName    = "Biconomy Forwarder"
+	Version               = "1"
 )
 
 var SignedTypes = apitypes.Types{
@@ -0,0 +1,108 @@
+package metax
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DappHandle is a registered DAPP (address and ABI) of a Bcnmy. It is
+// immutable, so its methods are safe for concurrent use, sharing the client's
+// chain connection, apiId table and nonce manager.
+type DappHandle struct {
+	b       *Bcnmy
+	address common.Address
+	abi     abi.ABI
+}
+
+// RegisterDapp parses jsonABI and registers the DAPP at address, replacing a
+// previous registration. Handles obtained before stay valid.
+func (b *Bcnmy) RegisterDapp(jsonABI string, address common.Address) (*DappHandle, error) {
+	parsed, err := abi.JSON(strings.NewReader(jsonABI))
+	if err != nil {
+		b.logger.WithError(err).Error("jsonABI parse failed")
+		return nil, err
+	}
+	dapp := &DappHandle{b: b, address: address, abi: parsed}
+	b.mu.Lock()
+	b.dapps[address] = dapp
+	b.mu.Unlock()
+	return dapp, nil
+}
+
+// Dapp returns the DAPP registered at address.
+func (b *Bcnmy) Dapp(address common.Address) (*DappHandle, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	dapp, ok := b.dapps[address]
+	return dapp, ok
+}
+
+// Dapps lists the registered DAPPs.
+func (b *Bcnmy) Dapps() []*DappHandle {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	dapps := make([]*DappHandle, 0, len(b.dapps))
+	for _, dapp := range b.dapps {
+		dapps = append(dapps, dapp)
+	}
+	return dapps
+}
+
+// defaultDapp is the DAPP chosen with WithDapp, an empty one when none was.
+func (b *Bcnmy) defaultDapp() *DappHandle {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.dapp == nil {
+		return &DappHandle{b: b}
+	}
+	return b.dapp
+}
+
+func (d *DappHandle) Address() common.Address {
+	return d.address
+}
+
+// ABI returns the parsed ABI, which must not be modified.
+func (d *DappHandle) ABI() abi.ABI {
+	return d.abi
+}
+
+func (d *DappHandle) RawTransact(signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return d.RawTransactContext(d.b.ctx, signer, method, params...)
+}
+
+func (d *DappHandle) RawTransactContext(ctx context.Context, signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return d.RawTransactWithOpts(ctx, nil, signer, method, params...)
+}
+
+func (d *DappHandle) Submit(signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	return d.SubmitContext(d.b.ctx, signer, method, params...)
+}
+
+func (d *DappHandle) SubmitContext(ctx context.Context, signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	return d.SubmitWithOpts(ctx, nil, signer, method, params...)
+}
+
+func (d *DappHandle) EnhanceTransact(from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return d.EnhanceTransactContext(d.b.ctx, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) EnhanceTransactContext(ctx context.Context, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return d.EnhanceTransactWithOpts(ctx, nil, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) SubmitEnhanced(from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return d.SubmitEnhancedContext(d.b.ctx, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) SubmitEnhancedContext(ctx context.Context, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return d.SubmitEnhancedWithOpts(ctx, nil, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) CheckLimits(from string, method string) (*CheckLimitResponse, error) {
+	return d.CheckLimitsContext(d.b.ctx, from, method)
+}
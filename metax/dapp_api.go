@@ -1,6 +1,7 @@
 package metax
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -37,10 +38,20 @@ type LimitInfo struct {
 }
 
 func (b *Bcnmy) CheckLimits(from string, method string) (*CheckLimitResponse, error) {
-	apiId, ok := b.apiID[fmt.Sprintf("%s-%s", b.address.Hex(), method)]
-	if !ok {
-		err := fmt.Errorf("ApiId not found for %s", method)
-		b.logger.Error(err.Error())
+	return b.CheckLimitsContext(b.ctx, from, method)
+}
+
+func (b *Bcnmy) CheckLimitsContext(ctx context.Context, from string, method string) (*CheckLimitResponse, error) {
+	return b.defaultDapp().CheckLimitsContext(ctx, from, method)
+}
+
+func (d *DappHandle) CheckLimitsContext(ctx context.Context, from string, method string) (*CheckLimitResponse, error) {
+	b := d.b
+	if err := b.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	apiId, err := d.resolveAPIIDOf(ctx, method)
+	if err != nil {
 		return nil, err
 	}
 
@@ -53,9 +64,9 @@ func (b *Bcnmy) CheckLimits(from string, method string) (*CheckLimitResponse, er
 		"userAddress": {from},
 		"apiId":       {apiId.ID},
 	}
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(ctx,
 		http.MethodGet,
-		fmt.Sprintf("%s?%s", CheckLimitURL, values.Encode()),
+		fmt.Sprintf("%s?%s", b.endpoints.APIURL(CheckLimitPath), values.Encode()),
 		nil,
 	)
 	if err != nil {
@@ -70,7 +81,10 @@ func (b *Bcnmy) CheckLimits(from string, method string) (*CheckLimitResponse, er
 	case ret := <-bodyCh:
 		err = json.Unmarshal(ret, &resp)
 		if err != nil {
-			return nil, fmt.Errorf("AddDestinationAddresses unmarshal failed, %v", err)
+			return nil, fmt.Errorf("CheckLimits unmarshal failed, %v", err)
+		}
+		if err := resp.Err(); err != nil {
+			return &resp, err
 		}
 		return &resp, nil
 	case err := <-errorCh:
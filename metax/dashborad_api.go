@@ -1,9 +1,9 @@
 package metax
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -17,6 +17,10 @@ type GeneralResponse struct {
 	ResponseCode int    `json:"responseCode"`
 }
 
+func (r *GeneralResponse) ok() bool {
+	return r != nil && (r.Code == http.StatusOK || r.ResponseCode == http.StatusOK)
+}
+
 type CreateDappRequest struct {
 	DappName             string `json:"dappName"`
 	NetworkId            string `json:"networkId"`
@@ -70,6 +74,10 @@ type DeleteMethodRequest struct {
 }
 
 func (b *Bcnmy) CreateDapp(data *CreateDappRequest) (*CreateDappResponse, error) {
+	return b.CreateDappContext(b.ctx, data)
+}
+
+func (b *Bcnmy) CreateDappContext(ctx context.Context, data *CreateDappRequest) (*CreateDappResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -79,7 +87,7 @@ func (b *Bcnmy) CreateDapp(data *CreateDappRequest) (*CreateDappResponse, error)
 		"networkId":            {data.NetworkId},
 		"enableBiconomyWallet": {strconv.FormatBool(data.EnableBiconomyWallet)},
 	}
-	req, err := http.NewRequest(http.MethodPost, CreateDappPublicURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(CreateDappPublicPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("CreateDapp NewRequest failed")
 		return nil, err
@@ -103,6 +111,10 @@ func (b *Bcnmy) CreateDapp(data *CreateDappRequest) (*CreateDappResponse, error)
 }
 
 func (b *Bcnmy) AddContract(data *AddContractRequest) (*GeneralResponse, error) {
+	return b.AddContractContext(b.ctx, data)
+}
+
+func (b *Bcnmy) AddContractContext(ctx context.Context, data *AddContractRequest) (*GeneralResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -116,7 +128,7 @@ func (b *Bcnmy) AddContract(data *AddContractRequest) (*GeneralResponse, error)
 		"metaTransactionType": {data.MetaTransactionType},
 		"abi":                 {data.ABI},
 	}
-	req, err := http.NewRequest(http.MethodPost, AddContractURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(AddContractPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("AddContract NewRequest failed")
 		return nil, err
@@ -140,6 +152,10 @@ func (b *Bcnmy) AddContract(data *AddContractRequest) (*GeneralResponse, error)
 }
 
 func (b *Bcnmy) AddMethod(data *AddMethodRequest) (*AddMethodResponse, error) {
+	return b.AddMethodContext(b.ctx, data)
+}
+
+func (b *Bcnmy) AddMethodContext(ctx context.Context, data *AddMethodRequest) (*AddMethodResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -152,7 +168,7 @@ func (b *Bcnmy) AddMethod(data *AddMethodRequest) (*AddMethodResponse, error) {
 		"contractAddress": {data.ContractAddress},
 		"method":          {data.Method},
 	}
-	req, err := http.NewRequest(http.MethodPost, AddMethodURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(AddMethodPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("AddMethod NewRequest failed")
 		return nil, err
@@ -168,6 +184,7 @@ func (b *Bcnmy) AddMethod(data *AddMethodRequest) (*AddMethodResponse, error) {
 		if err != nil {
 			return nil, fmt.Errorf("AddMethod unmarshal failed, %v", err)
 		}
+		b.addMethodAPIIDs(data, &resp)
 		return &resp, nil
 	case err := <-errorCh:
 		b.logger.Error(err.Error())
@@ -176,11 +193,15 @@ func (b *Bcnmy) AddMethod(data *AddMethodRequest) (*AddMethodResponse, error) {
 }
 
 func (b *Bcnmy) DeleteContract(data *DeleteContractRequest) (*GeneralResponse, error) {
+	return b.DeleteContractContext(b.ctx, data)
+}
+
+func (b *Bcnmy) DeleteContractContext(ctx context.Context, data *DeleteContractRequest) (*GeneralResponse, error) {
 	body := url.Values{
 		"contractAddress": {data.ContractAddress},
 		"contractType":    {data.ContractType},
 	}
-	req, err := http.NewRequest(http.MethodDelete, DeleteContractURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, b.endpoints.APIURL(DeleteContractPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("DeleteContract NewRequest failed")
 		return nil, err
@@ -189,27 +210,32 @@ func (b *Bcnmy) DeleteContract(data *DeleteContractRequest) (*GeneralResponse, e
 	req.Header.Set("authToken", b.authToken)
 	req.Header.Set("apiKey", b.apiKey)
 
-	res, err := b.httpClient.Do(req)
+	_, replyData, err := b.doHttpx(b.httpClient, req)
 	if err != nil {
 		b.logger.WithError(err).Error("HttpClient request to DeleteContract failed")
 		return nil, err
 	}
-	defer res.Body.Close()
-	replyData, err := io.ReadAll(res.Body)
 	var ret *GeneralResponse
 	if err := json.Unmarshal(replyData, &ret); err != nil {
 		b.logger.WithError(err).Error("json unmarshal body data failed")
 		return nil, err
 	}
+	if ret.ok() {
+		b.deleteAPIIDs(data.ContractAddress, "")
+	}
 	return ret, nil
 }
 
 func (b *Bcnmy) DeleteMethod(data *DeleteMethodRequest) (*GeneralResponse, error) {
+	return b.DeleteMethodContext(b.ctx, data)
+}
+
+func (b *Bcnmy) DeleteMethodContext(ctx context.Context, data *DeleteMethodRequest) (*GeneralResponse, error) {
 	body := url.Values{
 		"contractAddress": {data.ContractAddress},
 		"method":          {data.Method},
 	}
-	req, err := http.NewRequest(http.MethodDelete, DeleteMethodURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, b.endpoints.APIURL(DeleteMethodPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("DeleteMethod NewRequest failed")
 		return nil, err
@@ -218,17 +244,18 @@ func (b *Bcnmy) DeleteMethod(data *DeleteMethodRequest) (*GeneralResponse, error
 	req.Header.Set("authToken", b.authToken)
 	req.Header.Set("apiKey", b.apiKey)
 
-	res, err := b.httpClient.Do(req)
+	_, replyData, err := b.doHttpx(b.httpClient, req)
 	if err != nil {
 		b.logger.WithError(err).Error("HttpClient request to DeleteMethod failed")
 		return nil, err
 	}
-	defer res.Body.Close()
-	replyData, err := io.ReadAll(res.Body)
 	var ret *GeneralResponse
 	if err := json.Unmarshal(replyData, &ret); err != nil {
 		b.logger.WithError(err).Error("json unmarshal body data failed")
 		return nil, err
 	}
+	if ret.ok() {
+		b.deleteAPIIDs(data.ContractAddress, data.Method)
+	}
 	return ret, nil
 }
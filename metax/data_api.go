@@ -1,6 +1,7 @@
 package metax
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -46,6 +47,10 @@ type UserLimitResponse struct {
 }
 
 func (b *Bcnmy) GetUniqueUserData(data *UniqueUserDataRequest) (*UniqueUserDataResponse, error) {
+	return b.GetUniqueUserDataContext(b.ctx, data)
+}
+
+func (b *Bcnmy) GetUniqueUserDataContext(ctx context.Context, data *UniqueUserDataRequest) (*UniqueUserDataResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(errorCh)
@@ -55,7 +60,7 @@ func (b *Bcnmy) GetUniqueUserData(data *UniqueUserDataRequest) (*UniqueUserDataR
 		"startDate": {data.StartDate},
 		"endDate":   {data.EndDate},
 	}
-	req, err := http.NewRequest(http.MethodGet, UniqueUserDataURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoints.DataURL(UniqueUserDataPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("GetUniqueUserData NewRequest failed")
 		return nil, err
@@ -79,6 +84,10 @@ func (b *Bcnmy) GetUniqueUserData(data *UniqueUserDataRequest) (*UniqueUserDataR
 }
 
 func (b *Bcnmy) GetUserLimit(data *UserLimitRequest) (*UserLimitResponse, error) {
+	return b.GetUserLimitContext(b.ctx, data)
+}
+
+func (b *Bcnmy) GetUserLimitContext(ctx context.Context, data *UserLimitRequest) (*UserLimitResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(errorCh)
@@ -88,7 +97,7 @@ func (b *Bcnmy) GetUserLimit(data *UserLimitRequest) (*UserLimitResponse, error)
 		"signerAddress": {data.SignerAddress},
 		"apiId":         {data.ApiId},
 	}
-	req, err := http.NewRequest(http.MethodGet, UserLimitURL, strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoints.DataURL(UserLimitPath), strings.NewReader(body.Encode()))
 	if err != nil {
 		b.logger.WithError(err).Error("GetUserLimit NewRequest failed")
 		return nil, err
@@ -0,0 +1,93 @@
+package metax
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// DefaultDeadlineTTL is how far ahead RawTransact and Submit set the
+// deadline of the forward requests they sign.
+const DefaultDeadlineTTL = time.Hour
+
+// maxSecondsDeadline is the largest deadline taken for unix seconds, in year
+// 5138. Millisecond timestamps of today are above it.
+const maxSecondsDeadline = 100000000000
+
+// DeadlinePolicy configures the deadlines of forward requests. The forwarder
+// compares them, in unix seconds, with the block timestamp.
+type DeadlinePolicy struct {
+	// TTL is how far ahead signed deadlines are set, DefaultDeadlineTTL when zero
+	TTL time.Duration
+	// ClockSkew is the tolerated difference between the local clock and the
+	// block timestamps: deadlines must be more than ClockSkew ahead
+	ClockSkew time.Duration
+	// MaxTTL rejects deadlines further ahead, beyond the skew, no limit when zero
+	MaxTTL time.Duration
+}
+
+func (p DeadlinePolicy) ttl() time.Duration {
+	if p.TTL == 0 {
+		return DefaultDeadlineTTL
+	}
+	return p.TTL
+}
+
+func (p DeadlinePolicy) validate() error {
+	if p.TTL < 0 || p.ClockSkew < 0 || p.MaxTTL < 0 {
+		return fmt.Errorf("DeadlinePolicy durations must not be negative: %+v", p)
+	}
+	if p.ttl() <= p.ClockSkew {
+		return fmt.Errorf("DeadlinePolicy TTL %v must exceed the clock skew %v", p.ttl(), p.ClockSkew)
+	}
+	if p.MaxTTL != 0 && p.ttl() > p.MaxTTL {
+		return fmt.Errorf("DeadlinePolicy TTL %v above MaxTTL %v", p.ttl(), p.MaxTTL)
+	}
+	return nil
+}
+
+// check rejects a deadline in milliseconds, not ClockSkew ahead of now or
+// further ahead than MaxTTL.
+func (p DeadlinePolicy) check(deadline *big.Int, now time.Time) error {
+	if deadline == nil {
+		return fmt.Errorf("%w: deadline is required", ErrDeadlineInvalid)
+	}
+	if deadline.Cmp(big.NewInt(maxSecondsDeadline)) > 0 {
+		return fmt.Errorf("%w: deadline %v is not in unix seconds, milliseconds?", ErrDeadlineInvalid, deadline)
+	}
+	if deadline.Cmp(big.NewInt(now.Add(p.ClockSkew).Unix())) <= 0 {
+		return fmt.Errorf("%w: deadline %v, now %v with clock skew %v", ErrDeadlineExpired, deadline, now.Unix(), p.ClockSkew)
+	}
+	if p.MaxTTL != 0 && deadline.Cmp(big.NewInt(now.Add(p.MaxTTL+p.ClockSkew).Unix())) > 0 {
+		return fmt.Errorf("%w: deadline %v more than %v ahead", ErrDeadlineInvalid, deadline, p.MaxTTL)
+	}
+	return nil
+}
+
+// ValidateDeadline checks a forward request deadline against the client's
+// DeadlinePolicy, failing with ErrDeadlineExpired or ErrDeadlineInvalid.
+func (b *Bcnmy) ValidateDeadline(deadline *big.Int) error {
+	return b.deadlinePolicy.check(deadline, time.Now())
+}
+
+// deadline is the deadline to sign: opts.Deadline, or the policy TTL from now.
+func (b *Bcnmy) deadline(opts *TransactOpts) (*big.Int, error) {
+	now := time.Now()
+	deadline := big.NewInt(now.Add(b.deadlinePolicy.ttl()).Unix())
+	if opts != nil && !opts.Deadline.IsZero() {
+		deadline = big.NewInt(opts.Deadline.Unix())
+	}
+	if err := b.deadlinePolicy.check(deadline, now); err != nil {
+		return nil, err
+	}
+	return deadline, nil
+}
+
+// nonceExpiry is when a relayed request with deadline can no longer be
+// executed, DefaultDeadlineTTL from now when it has none in seconds.
+func (b *Bcnmy) nonceExpiry(deadline *big.Int) time.Time {
+	if deadline == nil || deadline.Sign() <= 0 || deadline.Cmp(big.NewInt(maxSecondsDeadline)) > 0 {
+		return time.Now().Add(DefaultDeadlineTTL)
+	}
+	return time.Unix(deadline.Int64(), 0).Add(b.deadlinePolicy.ClockSkew)
+}
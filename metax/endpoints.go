@@ -0,0 +1,81 @@
+package metax
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Endpoints is the set of Biconomy hosts a Bcnmy talks to. Every request
+// builder joins one of these hosts with the matching `*Path` constant.
+type Endpoints struct {
+	APIHost         string `json:"apiHost"`         // meta-api, meta-tx, dashboard public api, whitelist
+	DataHost        string `json:"dataHost"`        // unique user data, user limit, gas tank
+	BackendHost     string `json:"backendHost"`     // dashboard backend login and dapp list
+	GaslessMetaHost string `json:"gaslessMetaHost"` // v1 sdk native meta-tx and transaction status
+}
+
+const (
+	ProductionProfile = "production"
+)
+
+var DefaultEndpoints = Endpoints{
+	APIHost:         ProductionAPIHost,
+	DataHost:        ProductionDataHost,
+	BackendHost:     ProductionBackendHost,
+	GaslessMetaHost: ProductionGaslessMetaHost,
+}
+
+// EndpointProfiles holds the named endpoint sets selectable with
+// NewBcnmyWithProfile. Register staging, proxy or local fake hosts here
+// before constructing the client.
+var EndpointProfiles = map[string]Endpoints{
+	ProductionProfile: DefaultEndpoints,
+}
+
+func LookupEndpoints(profile string) (Endpoints, error) {
+	endpoints, ok := EndpointProfiles[profile]
+	if !ok {
+		return Endpoints{}, fmt.Errorf("Endpoint profile not found: %s", profile)
+	}
+	return endpoints, nil
+}
+
+func (e Endpoints) Validate() error {
+	hosts := map[string]string{
+		"APIHost":         e.APIHost,
+		"DataHost":        e.DataHost,
+		"BackendHost":     e.BackendHost,
+		"GaslessMetaHost": e.GaslessMetaHost,
+	}
+	for name, host := range hosts {
+		u, err := url.Parse(host)
+		if err != nil {
+			return fmt.Errorf("Endpoints %s %q invalid: %v", name, host, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("Endpoints %s %q must be an absolute URL", name, host)
+		}
+	}
+	return nil
+}
+
+func joinURL(host string, path string) string {
+	return strings.TrimRight(host, "/") + path
+}
+
+func (e Endpoints) APIURL(path string) string {
+	return joinURL(e.APIHost, path)
+}
+
+func (e Endpoints) DataURL(path string) string {
+	return joinURL(e.DataHost, path)
+}
+
+func (e Endpoints) BackendURL(path string) string {
+	return joinURL(e.BackendHost, path)
+}
+
+func (e Endpoints) GaslessMetaURL(path string) string {
+	return joinURL(e.GaslessMetaHost, path)
+}
@@ -0,0 +1,202 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/oblzh/bcnmy-go/abi/erc20forwarder"
+	"github.com/oblzh/bcnmy-go/abi/token"
+)
+
+// FeeMultiplierBase is the denominator of the fee manager's multiplier.
+const FeeMultiplierBase = 10000
+
+// FeeQuote is the ERC20 fee of a forward request paid through the ERC20
+// forwarder's fee proxy. It mirrors the proxy's charge
+//
+//	(gasUsed + BaseGas + TransferHandlerGas) * TokenGasPrice * FeeMultiplier / 10000
+//
+// with gasUsed bounded by TxGas, so Fee is the most the user is charged.
+type FeeQuote struct {
+	Token    common.Address
+	FeeProxy common.Address
+	// GasPrice, TokenPrice and TokenOracleDecimals are what TokenGasPrice was
+	// derived from, unset when the request came with its own TokenGasPrice
+	GasPrice            *big.Int
+	TokenPrice          *big.Int
+	TokenOracleDecimals uint8
+	TokenGasPrice       *big.Int
+	TxGas               uint64
+	BaseGas             *big.Int
+	TransferHandlerGas  *big.Int
+	FeeMultiplier       uint16
+	Fee                 *big.Int
+}
+
+func (b *Bcnmy) QuoteFee(user common.Address, feeToken common.Address, txGas uint64) (*FeeQuote, error) {
+	return b.QuoteFeeContext(b.ctx, user, feeToken, txGas)
+}
+
+// QuoteFeeContext prices txGas gas of user in feeToken at the current gas
+// price and oracle price of the fee proxy set with WithERC20FeeProxy.
+func (b *Bcnmy) QuoteFeeContext(ctx context.Context, user common.Address, feeToken common.Address, txGas uint64) (*FeeQuote, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	return b.quoteFee(ctx, user, feeToken, txGas, nil)
+}
+
+// quoteFee quotes at tokenGasPrice, or at the oracle derived one when nil.
+func (b *Bcnmy) quoteFee(ctx context.Context, user common.Address, feeToken common.Address, txGas uint64, tokenGasPrice *big.Int) (*FeeQuote, error) {
+	if b.feeProxy == (common.Address{}) {
+		err := fmt.Errorf("ERC20 fee proxy not configured, see WithERC20FeeProxy")
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	proxy, err := erc20forwarder.NewERC20FeeProxyCaller(b.feeProxy, b.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	feeManagerAddress, err := proxy.FeeManager(callOpts)
+	if err != nil {
+		b.logger.WithError(err).Error("ERC20FeeProxy feeManager failed")
+		return nil, err
+	}
+	feeManager, err := erc20forwarder.NewFeeManagerCaller(feeManagerAddress, b.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := feeManager.GetTokenAllowed(callOpts, feeToken)
+	if err != nil {
+		b.logger.WithError(err).Error("FeeManager getTokenAllowed failed")
+		return nil, err
+	}
+	if !allowed {
+		err := fmt.Errorf("%w: %s", ErrFeeTokenNotAllowed, feeToken.Hex())
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+
+	quote := &FeeQuote{Token: feeToken, FeeProxy: b.feeProxy, TxGas: txGas, TokenGasPrice: tokenGasPrice}
+	if quote.FeeMultiplier, err = feeManager.GetFeeMultiplier(callOpts, user, feeToken); err != nil {
+		b.logger.WithError(err).Error("FeeManager getFeeMultiplier failed")
+		return nil, err
+	}
+	if quote.BaseGas, err = proxy.BaseGas(callOpts); err != nil {
+		b.logger.WithError(err).Error("ERC20FeeProxy baseGas failed")
+		return nil, err
+	}
+	if quote.TransferHandlerGas, err = proxy.TransferHandlerGas(callOpts, feeToken); err != nil {
+		b.logger.WithError(err).Error("ERC20FeeProxy transferHandlerGas failed")
+		return nil, err
+	}
+	if quote.TokenGasPrice == nil {
+		if err := b.quoteTokenGasPrice(ctx, proxy, quote); err != nil {
+			return nil, err
+		}
+	}
+
+	gas := new(big.Int).SetUint64(txGas)
+	gas.Add(gas, quote.BaseGas)
+	gas.Add(gas, quote.TransferHandlerGas)
+	fee := gas.Mul(gas, quote.TokenGasPrice)
+	fee.Mul(fee, big.NewInt(int64(quote.FeeMultiplier)))
+	quote.Fee = fee.Div(fee, big.NewInt(FeeMultiplierBase))
+	return quote, nil
+}
+
+// quoteTokenGasPrice converts the current gas price into token units per gas
+// with the oracle aggregator of the fee proxy.
+func (b *Bcnmy) quoteTokenGasPrice(ctx context.Context, proxy *erc20forwarder.ERC20FeeProxyCaller, quote *FeeQuote) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	oracleAddress, err := proxy.OracleAggregator(callOpts)
+	if err != nil {
+		b.logger.WithError(err).Error("ERC20FeeProxy oracleAggregator failed")
+		return err
+	}
+	oracle, err := erc20forwarder.NewOracleAggregatorCaller(oracleAddress, b.ethClient)
+	if err != nil {
+		return err
+	}
+	if quote.TokenPrice, err = oracle.GetTokenPrice(callOpts, quote.Token); err != nil {
+		b.logger.WithError(err).Error("OracleAggregator getTokenPrice failed")
+		return err
+	}
+	if quote.TokenPrice.Sign() <= 0 {
+		err := fmt.Errorf("Oracle price of %s is %v", quote.Token.Hex(), quote.TokenPrice)
+		b.logger.Error(err.Error())
+		return err
+	}
+	if quote.TokenOracleDecimals, err = oracle.GetTokenOracleDecimals(callOpts, quote.Token); err != nil {
+		b.logger.WithError(err).Error("OracleAggregator getTokenOracleDecimals failed")
+		return err
+	}
+	if quote.GasPrice, err = b.ethClient.SuggestGasPrice(ctx); err != nil {
+		b.logger.WithError(err).Error("SuggestGasPrice failed")
+		return err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(quote.TokenOracleDecimals)), nil)
+	tokenGasPrice := new(big.Int).Mul(quote.GasPrice, scale)
+	quote.TokenGasPrice = tokenGasPrice.Div(tokenGasPrice, quote.TokenPrice)
+	return nil
+}
+
+// CheckFeeFunds checks that user allowed the fee proxy to take quote.Fee and
+// holds enough of the token for the fee plus spend, the amount the forwarded
+// call itself transfers (nil for none).
+func (b *Bcnmy) CheckFeeFunds(ctx context.Context, quote *FeeQuote, user common.Address, spend *big.Int) error {
+	if err := b.ensureChain(ctx); err != nil {
+		return err
+	}
+	erc20, err := token.NewERC20Caller(quote.Token, b.ethClient)
+	if err != nil {
+		return err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	allowance, err := erc20.Allowance(callOpts, user, quote.FeeProxy)
+	if err != nil {
+		b.logger.WithError(err).Error("ERC20 allowance failed")
+		return err
+	}
+	if allowance.Cmp(quote.Fee) < 0 {
+		err := &FeeFundsError{Token: quote.Token, Account: user, Spender: quote.FeeProxy, Have: allowance, Need: quote.Fee}
+		b.logger.Error(err.Error())
+		return err
+	}
+	balance, err := erc20.BalanceOf(callOpts, user)
+	if err != nil {
+		b.logger.WithError(err).Error("ERC20 balanceOf failed")
+		return err
+	}
+	need := new(big.Int).Set(quote.Fee)
+	if spend != nil {
+		need.Add(need, spend)
+	}
+	if balance.Cmp(need) < 0 {
+		err := &FeeFundsError{Token: quote.Token, Account: user, Have: balance, Need: need}
+		b.logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// payFee quotes the fee of an ERC20 paid request and checks user can pay it.
+func (b *Bcnmy) payFee(ctx context.Context, opts *TransactOpts, user common.Address, feeToken common.Address, txGas uint64, tokenGasPrice *big.Int) (*FeeQuote, error) {
+	if _, _, err := b.relayChain(opts, &MetaTxMessage{Token: feeToken}); err != nil {
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	quote, err := b.quoteFee(ctx, user, feeToken, txGas, tokenGasPrice)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.CheckFeeFunds(ctx, quote, user, opts.tokenSpend()); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
@@ -0,0 +1,110 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/oblzh/bcnmy-go/abi/recipient"
+)
+
+// AppendSender is the calldata an EIP-2771 recipient receives from the
+// forwarder for a request of from: data followed by the 20 bytes of from,
+// which the recipient's _msgSender() reads back.
+func AppendSender(data []byte, from common.Address) []byte {
+	calldata := make([]byte, 0, len(data)+common.AddressLength)
+	calldata = append(calldata, data...)
+	return append(calldata, from.Bytes()...)
+}
+
+// SplitSender is the inverse of AppendSender.
+func SplitSender(calldata []byte) ([]byte, common.Address, error) {
+	if len(calldata) < common.AddressLength {
+		return nil, common.Address{}, fmt.Errorf("Calldata of %v bytes has no appended sender", len(calldata))
+	}
+	split := len(calldata) - common.AddressLength
+	return calldata[:split], common.BytesToAddress(calldata[split:]), nil
+}
+
+// ForwardCall is the call the trusted forwarder makes to the DAPP when it
+// executes a request of from with data, for estimating or simulating the
+// forwarded call alone.
+func (d *DappHandle) ForwardCall(from common.Address, data []byte) ethereum.CallMsg {
+	return ethereum.CallMsg{
+		From: d.b.trustedForwarder.Address,
+		To:   &d.address,
+		Data: AppendSender(data, from),
+	}
+}
+
+func (b *Bcnmy) IsTrustedForwarder(ctx context.Context, target common.Address) (bool, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return false, err
+	}
+	caller, err := recipient.NewRecipientCaller(target, b.ethClient)
+	if err != nil {
+		return false, err
+	}
+	trusted, err := caller.IsTrustedForwarder(&bind.CallOpts{Context: ctx}, b.trustedForwarder.Address)
+	if err != nil {
+		b.logger.WithError(err).Errorf("isTrustedForwarder of %s failed", target.Hex())
+		return false, err
+	}
+	return trusted, nil
+}
+
+// VersionRecipient is the EIP-2771 recipient version reported by target.
+func (b *Bcnmy) VersionRecipient(ctx context.Context, target common.Address) (string, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return "", err
+	}
+	caller, err := recipient.NewRecipientCaller(target, b.ethClient)
+	if err != nil {
+		return "", err
+	}
+	version, err := caller.VersionRecipient(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		b.logger.WithError(err).Errorf("versionRecipient of %s failed", target.Hex())
+		return "", err
+	}
+	return version, nil
+}
+
+// CheckTrustedForwarder fails with a *ForwarderNotTrustedError unless the
+// DAPP is an EIP-2771 recipient trusting the configured forwarder. Positive
+// answers are remembered until Refresh.
+func (d *DappHandle) CheckTrustedForwarder(ctx context.Context) error {
+	b := d.b
+	if err := b.ensureChain(ctx); err != nil {
+		return err
+	}
+	b.mu.RLock()
+	trusted := b.trustedBy[d.address]
+	b.mu.RUnlock()
+	if trusted {
+		return nil
+	}
+
+	trusted, err := b.IsTrustedForwarder(ctx, d.address)
+	if err != nil || !trusted {
+		err := &ForwarderNotTrustedError{Dapp: d.address, Forwarder: b.trustedForwarder.Address, Err: err}
+		b.logger.Error(err.Error())
+		return err
+	}
+	b.mu.Lock()
+	b.trustedBy[d.address] = true
+	b.mu.Unlock()
+	return nil
+}
+
+// ensureTrusted runs CheckTrustedForwarder unless WithTrustedForwarderCheck
+// turned it off.
+func (d *DappHandle) ensureTrusted(ctx context.Context) error {
+	if !d.b.checkTrusted {
+		return nil
+	}
+	return d.CheckTrustedForwarder(ctx)
+}
@@ -0,0 +1,258 @@
+package metax
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+/*
+Limit codes returned by checkLimits and meta-tx/native, see dapp_api.go
+
+	150	when DApp limits are exhausted
+	151	when User limits are exhausted
+	152	when API/User limits are exhausted
+*/
+const (
+	DappLimitExhaustedCode = 150
+	UserLimitExhaustedCode = 151
+	APILimitExhaustedCode  = 152
+)
+
+var (
+	ErrLimitExhausted      = errors.New("Limit exhausted")
+	ErrDappLimitExhausted  = errors.New("DApp limit exhausted")
+	ErrUserLimitExhausted  = errors.New("User limit exhausted")
+	ErrAPILimitExhausted   = errors.New("API limit exhausted")
+	ErrUnauthorized        = errors.New("Unauthorized")
+	ErrApiIdNotFound       = errors.New("ApiId not found")
+	ErrChainNotSupported   = errors.New("Chain ID not supported")
+	ErrRelayerRejected     = errors.New("Relayer rejected transaction")
+	ErrSignatureInvalid    = errors.New("Signature invalid")
+	ErrDeadlineExpired     = errors.New("Deadline expired")
+	ErrDeadlineInvalid     = errors.New("Deadline invalid")
+	ErrDappMismatch        = errors.New("Forward request target is not the dapp")
+	ErrSimulationReverted  = errors.New("Simulation reverted")
+	ErrTxDropped           = errors.New("Transaction dropped")
+	ErrMethodNotFound      = errors.New("Method not found in dapp ABI")
+	ErrAmbiguousMethod     = errors.New("Method matches several ABI overloads")
+	ErrMethodMismatch      = errors.New("Forward request data does not call the method")
+	ErrFeeTokenNotAllowed  = errors.New("Fee token not allowed")
+	ErrFeeAllowance        = errors.New("Fee proxy allowance insufficient")
+	ErrFeeBalance          = errors.New("Token balance insufficient for fee")
+	ErrFeeRelayUnavailable = errors.New("ERC20 fee requests need a self relayer")
+	ErrForwarderNotTrusted = errors.New("Dapp does not trust the forwarder")
+	ErrNonceUnavailable    = errors.New("Forward request nonce is used or out of order")
+	ErrGasPriceAboveMax    = errors.New("Gas price above the self relay MaxGasPrice")
+)
+
+func isLimitCode(code int) bool {
+	return code == DappLimitExhaustedCode || code == UserLimitExhaustedCode || code == APILimitExhaustedCode
+}
+
+// LimitError reports an exhausted DApp, User or API limit. Exactly one of
+// MetaTxResponse and CheckLimitResponse is set, depending on which call
+// reported it.
+type LimitError struct {
+	Code               int
+	Message            string
+	Limit              LimitInfo
+	MetaTxResponse     *MetaTxResponse
+	CheckLimitResponse *CheckLimitResponse
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Message: %s, Code: %v, Limit: %v", e.Message, e.Code, e.Limit)
+}
+
+func (e *LimitError) Is(target error) bool {
+	switch target {
+	case ErrLimitExhausted:
+		return true
+	case ErrDappLimitExhausted:
+		return e.Code == DappLimitExhaustedCode
+	case ErrUserLimitExhausted:
+		return e.Code == UserLimitExhaustedCode
+	case ErrAPILimitExhausted:
+		return e.Code == APILimitExhaustedCode
+	case ErrRelayerRejected:
+		return e.MetaTxResponse != nil
+	}
+	return false
+}
+
+// RelayerError is returned when Biconomy answers a relay request without a
+// transaction hash for any reason other than an exhausted limit.
+type RelayerError struct {
+	Response *MetaTxResponse
+}
+
+func (e *RelayerError) Error() string {
+	return fmt.Sprintf("Message: %s, Code: %v, Limit: %v", e.Response.Message, e.Response.Code, e.Response.Limit)
+}
+
+func (e *RelayerError) Is(target error) bool {
+	switch target {
+	case ErrRelayerRejected:
+		return true
+	case ErrUnauthorized:
+		return e.Response.Code == 401 || e.Response.Code == 403
+	}
+	return false
+}
+
+// HTTPStatusError is returned for a non-200 HTTP answer.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%v", e.StatusCode)
+}
+
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrUnauthorized && (e.StatusCode == 401 || e.StatusCode == 403)
+}
+
+// SimulationError is returned when the pre-flight of a forward request
+// reverted, in the forwarder signature check or in the forwarded call.
+type SimulationError struct {
+	Result *SimulationResult
+}
+
+func (e *SimulationError) Error() string {
+	stage := "execution"
+	if !e.Result.Verified {
+		stage = "verification"
+	}
+	if e.Result.RevertReason != "" {
+		return fmt.Sprintf("Simulation reverted in %s: %s", stage, e.Result.RevertReason)
+	}
+	return fmt.Sprintf("Simulation reverted in %s: %s", stage, hexutil.Encode(e.Result.RevertData))
+}
+
+func (e *SimulationError) Is(target error) bool {
+	switch target {
+	case ErrSimulationReverted:
+		return true
+	case ErrSignatureInvalid:
+		return !e.Result.Verified
+	}
+	return false
+}
+
+type ApiIdNotFoundError struct {
+	Address common.Address
+	Method  string
+}
+
+func (e *ApiIdNotFoundError) Error() string {
+	return fmt.Sprintf("ApiId not found for %s on %s", e.Method, e.Address.Hex())
+}
+
+func (e *ApiIdNotFoundError) Is(target error) bool {
+	return target == ErrApiIdNotFound
+}
+
+// FeeFundsError reports an ERC20 paid request the user cannot pay: the
+// allowance of Spender (the fee proxy) or, when Spender is zero, the balance
+// is below Need.
+type FeeFundsError struct {
+	Token   common.Address
+	Account common.Address
+	Spender common.Address
+	Have    *big.Int
+	Need    *big.Int
+}
+
+func (e *FeeFundsError) Error() string {
+	if e.Spender != (common.Address{}) {
+		return fmt.Sprintf("Allowance of %s to fee proxy %s is %v, fee needs %v of token %s", e.Account.Hex(), e.Spender.Hex(), e.Have, e.Need, e.Token.Hex())
+	}
+	return fmt.Sprintf("Balance of %s is %v, fee plus transfer need %v of token %s", e.Account.Hex(), e.Have, e.Need, e.Token.Hex())
+}
+
+func (e *FeeFundsError) Is(target error) bool {
+	if e.Spender != (common.Address{}) {
+		return target == ErrFeeAllowance
+	}
+	return target == ErrFeeBalance
+}
+
+// ForwarderNotTrustedError reports a DAPP that answered false to
+// isTrustedForwarder(Forwarder), or could not answer it, Err being set then.
+type ForwarderNotTrustedError struct {
+	Dapp      common.Address
+	Forwarder common.Address
+	Err       error
+}
+
+func (e *ForwarderNotTrustedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Dapp %s is not an EIP-2771 recipient of forwarder %s: %v", e.Dapp.Hex(), e.Forwarder.Hex(), e.Err)
+	}
+	return fmt.Sprintf("Dapp %s does not trust forwarder %s", e.Dapp.Hex(), e.Forwarder.Hex())
+}
+
+func (e *ForwarderNotTrustedError) Is(target error) bool {
+	return target == ErrForwarderNotTrusted
+}
+
+func (e *ForwarderNotTrustedError) Unwrap() error {
+	return e.Err
+}
+
+type ChainNotSupportedError struct {
+	ChainID *big.Int
+}
+
+func (e *ChainNotSupportedError) Error() string {
+	return fmt.Sprintf("Chain ID not supported: %v", e.ChainID)
+}
+
+func (e *ChainNotSupportedError) Is(target error) bool {
+	return target == ErrChainNotSupported
+}
+
+// Err returns the typed error matching a failed relay, nil when a
+// transaction hash was returned.
+func (m *MetaTxResponse) Err() error {
+	if m.TxHash != (common.Hash{}) {
+		return nil
+	}
+	if isLimitCode(m.Code) {
+		return &LimitError{
+			Code:    m.Code,
+			Message: m.Message,
+			Limit: LimitInfo{
+				Allowed:   m.Allowed,
+				Type:      m.Limit.Type,
+				ResetTime: m.Limit.ResetTime,
+				LimitLeft: m.Limit.LimitLeft,
+			},
+			MetaTxResponse: m,
+		}
+	}
+	return &RelayerError{Response: m}
+}
+
+// Err returns a *LimitError when one of the limits is exhausted.
+func (c *CheckLimitResponse) Err() error {
+	if !isLimitCode(c.ResponseCode) && !isLimitCode(c.Code) {
+		return nil
+	}
+	code := c.ResponseCode
+	if !isLimitCode(code) {
+		code = c.Code
+	}
+	return &LimitError{
+		Code:               code,
+		Message:            c.Message,
+		Limit:              c.Limit,
+		CheckLimitResponse: c,
+	}
+}
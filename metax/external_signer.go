@@ -0,0 +1,68 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ExternalSigner delegates signing to an external process speaking the
+// Clef JSON-RPC API, e.g. `clef --http` or its IPC socket.
+type ExternalSigner struct {
+	client  *rpc.Client
+	address common.Address
+	timeout time.Duration
+}
+
+// NewExternalSigner dials endpoint (http, ws or ipc path). Clef waits for
+// manual approval, so timeout should leave room for the operator.
+func NewExternalSigner(endpoint string, address common.Address, timeout time.Duration) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewExternalSignerFrom(client, address, timeout), nil
+}
+
+func NewExternalSignerFrom(client *rpc.Client, address common.Address, timeout time.Duration) *ExternalSigner {
+	return &ExternalSigner{
+		client:  client,
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (s *ExternalSigner) GetAddress() common.Address {
+	return s.address
+}
+
+func (s *ExternalSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var sig hexutil.Bytes
+	address := common.NewMixedcaseAddress(s.address)
+	err := s.client.CallContext(ctx, &sig, "account_signTypedData", &address, typedData)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("External signer returned %v bytes signature", len(sig))
+	}
+	if sig[64] == 0 || sig[64] == 1 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,142 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ForwardRequestPayload is a forward request built by BuildForwardRequest
+// for a frontend wallet to sign, and handed back to SubmitSigned with the
+// signature. It marshals to JSON for the round trip.
+type ForwardRequestPayload struct {
+	// TypedData is the eth_signTypedData_v4 parameter
+	TypedData apitypes.TypedData `json:"typedData"`
+	// Hash is the EIP-712 digest the wallet signs
+	Hash common.Hash `json:"hash"`
+	// Method is the canonical signature of the forwarded call
+	Method  string         `json:"method"`
+	Message *MetaTxMessage `json:"message"`
+}
+
+func (b *Bcnmy) BuildForwardRequest(from common.Address, method string, params ...interface{}) (*ForwardRequestPayload, error) {
+	return b.BuildForwardRequestContext(b.ctx, from, method, params...)
+}
+
+func (b *Bcnmy) BuildForwardRequestContext(ctx context.Context, from common.Address, method string, params ...interface{}) (*ForwardRequestPayload, error) {
+	return b.BuildForwardRequestWithOpts(ctx, nil, from, method, params...)
+}
+
+func (b *Bcnmy) BuildForwardRequestWithOpts(ctx context.Context, opts *TransactOpts, from common.Address, method string, params ...interface{}) (*ForwardRequestPayload, error) {
+	return b.defaultDapp().BuildForwardRequestWithOpts(ctx, opts, from, method, params...)
+}
+
+func (d *DappHandle) BuildForwardRequest(from common.Address, method string, params ...interface{}) (*ForwardRequestPayload, error) {
+	return d.BuildForwardRequestWithOpts(d.b.ctx, nil, from, method, params...)
+}
+
+// BuildForwardRequestWithOpts prepares the EIP-712 forward request of from
+// calling method like Submit does, with gas, optional ERC20 fee, deadline
+// and nonce, but leaves signing to the frontend. The nonce is held for the
+// payload during WithNonceHold, so concurrent builds for one signer get
+// distinct nonces, and SubmitSigned claims it. Once the hold expires, or
+// without one, the nonce is handed out again and SubmitSigned fails with
+// ErrNonceUnavailable when another request took it in between. With
+// SequentialNonces a payload is executed only after the ones built before
+// it, or after their holds expired.
+func (d *DappHandle) BuildForwardRequestWithOpts(ctx context.Context, opts *TransactOpts, from common.Address, method string, params ...interface{}) (*ForwardRequestPayload, error) {
+	b := d.b
+	if opts.signatureType() != SignatureEIP712Type {
+		return nil, fmt.Errorf("BuildForwardRequest only builds %s requests", SignatureEIP712Type)
+	}
+	built, err := d.prepareMessage(ctx, opts, from, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	hold := b.nonceHold
+	if until := time.Until(b.nonceExpiry(built.message.Deadline)); until < hold {
+		hold = until
+	}
+	var nonce *NonceReservation
+	if hold > 0 {
+		nonce, err = b.nonces.reserve(ctx, from, b.chainNonce(from))
+		if err == nil {
+			built.message.BatchId, built.message.BatchNonce = nonce.BatchId, nonce.BatchNonce
+		}
+	} else {
+		built.message.BatchId, built.message.BatchNonce, err = b.nonces.peek(ctx, from, b.chainNonce(from))
+	}
+	if err != nil {
+		b.logger.WithError(err).Errorf("GetNonce of %s failed", from.Hex())
+		return nil, fmt.Errorf("GetNonce of %s failed: %w", from.Hex(), err)
+	}
+	typedData := b.forwardTypedData(built.message)
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		nonce.Fail()
+		b.logger.WithError(err).Error("TypedDataAndHash failed")
+		return nil, err
+	}
+	if nonce != nil {
+		b.nonces.hold(nonce, hold)
+	}
+	return &ForwardRequestPayload{
+		TypedData: typedData,
+		Hash:      common.BytesToHash(digest),
+		Method:    built.method.Sig,
+		Message:   built.message,
+	}, nil
+}
+
+func (b *Bcnmy) SubmitSigned(payload *ForwardRequestPayload, signature []byte) (*Submission, error) {
+	return b.SubmitSignedContext(b.ctx, payload, signature)
+}
+
+func (b *Bcnmy) SubmitSignedContext(ctx context.Context, payload *ForwardRequestPayload, signature []byte) (*Submission, error) {
+	return b.SubmitSignedWithOpts(ctx, nil, payload, signature)
+}
+
+// SubmitSignedWithOpts verifies the wallet signature of a BuildForwardRequest
+// payload and relays it like SubmitEnhanced. The payload is checked from its
+// Message alone: TypedData and Hash are recomputed from it, and the DAPP it
+// targets must be registered.
+func (b *Bcnmy) SubmitSignedWithOpts(ctx context.Context, opts *TransactOpts, payload *ForwardRequestPayload, signature []byte) (*Submission, error) {
+	if payload == nil || payload.Message == nil {
+		return nil, fmt.Errorf("ForwardRequestPayload has no message")
+	}
+	if opts.signatureType() != SignatureEIP712Type {
+		return nil, fmt.Errorf("SubmitSigned only relays %s requests", SignatureEIP712Type)
+	}
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	d, ok := b.Dapp(payload.Message.To)
+	if !ok {
+		err := fmt.Errorf("%w: %s is not registered", ErrDappMismatch, payload.Message.To.Hex())
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	if payload.Message.BatchId == nil || payload.Message.BatchNonce == nil {
+		return nil, fmt.Errorf("%w: forward request has no nonce", ErrNonceUnavailable)
+	}
+	typedData := b.forwardTypedData(payload.Message)
+	structHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	// only a signed request may claim the nonce
+	if err := d.verifyMetaTx(opts, payload.Message.From.Hex(), signature, payload.Message); err != nil {
+		b.logger.WithError(err).Error("Verify MetaTxMessage failed")
+		return nil, err
+	}
+	nonce, err := b.nonces.claim(ctx, payload.Message.From, payload.Message.BatchId, payload.Message.BatchNonce, b.chainNonce(payload.Message.From))
+	if err != nil {
+		b.logger.WithError(err).Errorf("Claim nonce of %s failed", payload.Message.From.Hex())
+		return nil, err
+	}
+	return d.submitEnhanced(ctx, opts, payload.Message.From.Hex(), payload.Method, signature, payload.Message, structHash.String(), nonce)
+}
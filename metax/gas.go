@@ -0,0 +1,156 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasStrategy decides the TxGas of the forward requests RawTransact and
+// Submit sign, the gas the forwarder hands to the DAPP call. Clients use
+// DefaultForwarderGasStrategy unless WithGasStrategy sets another; the zero
+// value, passed explicitly, signs the raw EstimateGas of the call from the
+// signer without any buffer.
+type GasStrategy struct {
+	// ForwarderPath estimates the call as the forwarder makes it: from the
+	// forwarder, with the signer appended for _msgSender (ERC-2771)
+	ForwarderPath bool
+	// Multiplier scales the estimate, 1 when zero
+	Multiplier float64
+	// Buffer is added to the scaled estimate
+	Buffer uint64
+	// Max caps TxGas, no cap when zero
+	Max uint64
+	// Methods are static gas limits used instead of estimating, keyed by
+	// method name, canonical signature or 4-byte selector of any DAPP
+	Methods map[string]uint64
+	// Fallback is the TxGas when the estimation reverts, the revert is
+	// returned when zero
+	Fallback uint64
+}
+
+// DefaultForwarderGasStrategy estimates through the forwarder with a 20%
+// and 10000 gas buffer.
+var DefaultForwarderGasStrategy = GasStrategy{
+	ForwarderPath: true,
+	Multiplier:    1.2,
+	Buffer:        10000,
+}
+
+func (s GasStrategy) validate() error {
+	if s.Multiplier < 0 || math.IsNaN(s.Multiplier) || math.IsInf(s.Multiplier, 0) {
+		return fmt.Errorf("GasStrategy multiplier invalid: %v", s.Multiplier)
+	}
+	if s.Max != 0 && s.Fallback > s.Max {
+		return fmt.Errorf("GasStrategy fallback %v above max %v", s.Fallback, s.Max)
+	}
+	methods := make(map[string]string, len(s.Methods))
+	for method := range s.Methods {
+		key := normalizeMethod(method)
+		if other, ok := methods[key]; ok {
+			return fmt.Errorf("GasStrategy methods %q and %q are the same", other, method)
+		}
+		methods[key] = method
+	}
+	return nil
+}
+
+// staticGas is the limit configured for m, false when it is estimated. The
+// most specific key wins: signature, selector, then name.
+func (s GasStrategy) staticGas(m *abi.Method) (uint64, bool) {
+	if len(s.Methods) == 0 {
+		return 0, false
+	}
+	methods := make(map[string]uint64, len(s.Methods))
+	for method, gas := range s.Methods {
+		methods[normalizeMethod(method)] = gas
+	}
+	for _, key := range methodAPIKeys(m) {
+		if gas, ok := methods[key]; ok {
+			return gas, true
+		}
+	}
+	return 0, false
+}
+
+// apply buffers and caps an estimate.
+func (s GasStrategy) apply(estimate uint64) uint64 {
+	gas := estimate
+	if s.Multiplier != 0 {
+		scaled := math.Ceil(float64(estimate) * s.Multiplier)
+		if scaled >= math.MaxUint64 {
+			gas = math.MaxUint64
+		} else {
+			gas = uint64(scaled)
+		}
+	}
+	if gas > math.MaxUint64-s.Buffer {
+		gas = math.MaxUint64
+	} else {
+		gas += s.Buffer
+	}
+	return s.cap(gas)
+}
+
+func (s GasStrategy) cap(gas uint64) uint64 {
+	if s.Max != 0 && gas > s.Max {
+		return s.Max
+	}
+	return gas
+}
+
+func (o *TransactOpts) gasStrategy(b *Bcnmy) GasStrategy {
+	if o == nil || o.GasStrategy == nil {
+		return b.gasStrategy
+	}
+	return *o.GasStrategy
+}
+
+// EstimateTxGas is the TxGas RawTransact and Submit would sign for the call
+// of method from signer, following the client's or opts' GasStrategy.
+func (d *DappHandle) EstimateTxGas(ctx context.Context, opts *TransactOpts, from common.Address, method string, params ...interface{}) (uint64, error) {
+	if err := d.b.ensureChain(ctx); err != nil {
+		return 0, err
+	}
+	m, err := d.Method(method, params...)
+	if err != nil {
+		return 0, err
+	}
+	data, err := packMethod(m, params...)
+	if err != nil {
+		return 0, err
+	}
+	return d.txGas(ctx, opts.gasStrategy(d.b), from, m, data)
+}
+
+func (d *DappHandle) txGas(ctx context.Context, strategy GasStrategy, from common.Address, m *abi.Method, data []byte) (uint64, error) {
+	b := d.b
+	if err := strategy.validate(); err != nil {
+		return 0, err
+	}
+	if gas, ok := strategy.staticGas(m); ok {
+		return strategy.cap(gas), nil
+	}
+	callMsg := ethereum.CallMsg{
+		From: from,
+		To:   &d.address,
+		Data: data,
+	}
+	if strategy.ForwarderPath {
+		callMsg = d.ForwardCall(from, data)
+	}
+	estimate, err := b.ethClient.EstimateGas(ctx, callMsg)
+	if err != nil {
+		if _, reverted := revertDataOf(err); reverted && strategy.Fallback != 0 {
+			b.logger.WithError(err).Warnf("EstimateGas of %s reverted, using fallback %v", m.Sig, strategy.Fallback)
+			return strategy.Fallback, nil
+		}
+		b.logger.WithError(err).Error("EstimateGas failed")
+		return 0, err
+	}
+	return strategy.apply(estimate), nil
+}
@@ -1,24 +1,89 @@
 package metax
 
 import (
-	"fmt"
+	"encoding/json"
 	"io"
 	"net/http"
 )
 
+// doHttpx sends req through client, retrying according to the retry policy.
+// Non-idempotent requests are only resent when the failed attempt provably
+// never reached the server.
+func (b *Bcnmy) doHttpx(client *http.Client, req *http.Request) (int, []byte, error) {
+	ctx := req.Context()
+	idempotent := isIdempotent(req.Method)
+	group := endpointGroupOf(req.URL.Path)
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return 0, nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		res, replyData, err := b.doAttempt(client, attemptReq, group)
+		wait, retry := b.retryPolicy.NextBackoff(attempt, res, err)
+		if retry && !idempotent && !safeToResend(res, err) {
+			retry = false
+		}
+		if retry && req.Body != nil && req.GetBody == nil {
+			retry = false
+		}
+		if !retry {
+			if err != nil {
+				b.logger.WithError(err).Error("HttpClient request to failed")
+				return 0, nil, err
+			}
+			return res.StatusCode, replyData, nil
+		}
+		if err != nil {
+			b.logger.WithError(err).Warnf("HttpClient request to %s failed, attempt %v, retry in %v", req.URL.Path, attempt, wait)
+		} else {
+			b.logger.Warnf("HttpClient request to %s got %v, attempt %v, retry in %v", req.URL.Path, res.StatusCode, attempt, wait)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// doAttempt makes a single request within the rate limit of its endpoint
+// group, holding the in-flight slot until the body is read.
+func (b *Bcnmy) doAttempt(client *http.Client, req *http.Request, group EndpointGroup) (*http.Response, []byte, error) {
+	release, err := b.limiter.acquire(req.Context(), group)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	replyData, err := io.ReadAll(res.Body)
+	if err != nil {
+		b.logger.WithError(err).Error("io read request body failed")
+		return nil, nil, err
+	}
+	return res, replyData, nil
+}
+
 func (b *Bcnmy) asyncHttpx(req *http.Request, errorCh chan error, bodyCh chan []byte) {
 	go func() {
-		res, err := b.httpClient.Do(req)
+		statusCode, replyData, err := b.doHttpx(b.httpClient, req)
 		if err != nil {
-			b.logger.WithError(err).Error("HttpClient request to failed")
 			errorCh <- err
 			return
 		}
-		defer res.Body.Close()
-		replyData, err := io.ReadAll(res.Body)
-		if err != nil {
-			b.logger.WithError(err).Error("io read request body failed")
-			errorCh <- err
+		// error replies carry a JSON code and message, others did not reach the API
+		if statusCode != 200 && !json.Valid(replyData) {
+			errorCh <- &HTTPStatusError{StatusCode: statusCode, Body: replyData}
 			return
 		}
 		bodyCh <- replyData
@@ -27,21 +92,13 @@ func (b *Bcnmy) asyncHttpx(req *http.Request, errorCh chan error, bodyCh chan []
 
 func (b *Bcnmy) backendAsyncHttpx(req *http.Request, errorCh chan error, bodyCh chan []byte) {
 	go func() {
-		res, err := b.backendHttpClient.Do(req)
+		statusCode, replyData, err := b.doHttpx(b.backendHttpClient, req)
 		if err != nil {
-			b.logger.WithError(err).Error("HttpClient request to failed")
 			errorCh <- err
 			return
 		}
-		defer res.Body.Close()
-		if res.StatusCode != 200 {
-			errorCh <- fmt.Errorf("%v", res.StatusCode)
-			return
-		}
-		replyData, err := io.ReadAll(res.Body)
-		if err != nil {
-			b.logger.WithError(err).Error("io read request body failed")
-			errorCh <- err
+		if statusCode != 200 {
+			errorCh <- &HTTPStatusError{StatusCode: statusCode, Body: replyData}
 			return
 		}
 		bodyCh <- replyData
@@ -0,0 +1,68 @@
+package metax
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// KeystoreSigner signs with an account of a go-ethereum encrypted keystore
+// directory; the decrypted key never leaves the keystore.
+type KeystoreSigner struct {
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+// NewKeystoreSigner opens keydir and unlocks address with passphrase until
+// Lock is called.
+func NewKeystoreSigner(keydir string, address common.Address, passphrase string) (*KeystoreSigner, error) {
+	ks := keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP)
+	return NewKeystoreSignerFrom(ks, address, passphrase)
+}
+
+// NewKeystoreSignerFrom uses an already opened keystore.
+func NewKeystoreSignerFrom(ks *keystore.KeyStore, address common.Address, passphrase string) (*KeystoreSigner, error) {
+	account, err := ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	if err = ks.Unlock(account, passphrase); err != nil {
+		return nil, err
+	}
+	return &KeystoreSigner{
+		ks:      ks,
+		account: account,
+	}, nil
+}
+
+func (s *KeystoreSigner) GetAddress() common.Address {
+	return s.account.Address
+}
+
+func (s *KeystoreSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	hashBytes, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.ks.SignHash(s.account, hashBytes)
+	if err != nil {
+		return nil, err
+	}
+	if sig[64] == 0 || sig[64] == 1 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+func (s *KeystoreSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.ks.SignTx(s.account, tx, chainID)
+}
+
+// Lock drops the decrypted key from the keystore memory.
+func (s *KeystoreSigner) Lock() error {
+	return s.ks.Lock(s.account.Address)
+}
@@ -43,7 +43,7 @@ func (b *Bcnmy) GetMetaAPI(ctx context.Context) (*MetaAPIResponse, error) {
 	defer close(bodyCh)
 	defer close(errorCh)
 
-	req, err := http.NewRequest(http.MethodGet, MetaAPIURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoints.APIURL(MetaAPIPath), nil)
 	if err != nil {
 		b.logger.WithError(err).Error("MetaAPI NewRequest failed")
 		return nil, err
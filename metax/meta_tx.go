@@ -9,12 +9,13 @@ import (
 	"net/http"
 	"time"
 
-	ethereum "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
 )
 
 type MetaTxMessage struct {
@@ -66,7 +67,38 @@ func (m *MetaTxMessage) TypedData() apitypes.TypedDataMessage {
 	}
 }
 
+// ForwardRequest converts m to the forwarder contract struct.
+func (m *MetaTxMessage) ForwardRequest() (forwarder.ERC20ForwardRequestTypesERC20ForwardRequest, error) {
+	var req forwarder.ERC20ForwardRequestTypesERC20ForwardRequest
+	tokenGasPrice, ok := new(big.Int).SetString(m.TokenGasPrice, 10)
+	if !ok {
+		return req, fmt.Errorf("TokenGasPrice %q is not a decimal number", m.TokenGasPrice)
+	}
+	data, err := hexutil.Decode(m.Data)
+	if err != nil {
+		return req, fmt.Errorf("Data %q invalid: %v", m.Data, err)
+	}
+	if m.BatchId == nil || m.BatchNonce == nil || m.Deadline == nil {
+		return req, fmt.Errorf("BatchId, BatchNonce and Deadline are required")
+	}
+	return forwarder.ERC20ForwardRequestTypesERC20ForwardRequest{
+		From:          m.From,
+		To:            m.To,
+		Token:         m.Token,
+		TxGas:         new(big.Int).SetUint64(m.TxGas),
+		TokenGasPrice: tokenGasPrice,
+		BatchId:       m.BatchId,
+		BatchNonce:    m.BatchNonce,
+		Deadline:      m.Deadline,
+		Data:          data,
+	}, nil
+}
+
 func (b *Bcnmy) SendMetaNativeTx(data *MetaTxRequest) (*MetaTxResponse, error) {
+	return b.SendMetaNativeTxContext(b.ctx, data)
+}
+
+func (b *Bcnmy) SendMetaNativeTxContext(ctx context.Context, data *MetaTxRequest) (*MetaTxResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -77,7 +109,7 @@ func (b *Bcnmy) SendMetaNativeTx(data *MetaTxRequest) (*MetaTxResponse, error) {
 		b.logger.WithError(err).Error("json marshal `MetaTxRequest` data failed")
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, MetaTxNativeURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(MetaTxNativePath), bytes.NewBuffer(body))
 	if err != nil {
 		b.logger.Error("SendMetaNativeTx NewRequest failed")
 		return nil, err
@@ -92,8 +124,7 @@ func (b *Bcnmy) SendMetaNativeTx(data *MetaTxRequest) (*MetaTxResponse, error) {
 		if err != nil {
 			return nil, fmt.Errorf("SendMetaNativeTx unmarshal failed, %v", err)
 		}
-		if resp.TxHash == common.HexToHash("0x0") {
-			err := fmt.Errorf("Message: %s, Code: %v, Limit: %v", resp.Message, resp.Code, resp.Limit)
+		if err := resp.Err(); err != nil {
 			return &resp, err
 		}
 		return &resp, nil
@@ -103,52 +134,286 @@ func (b *Bcnmy) SendMetaNativeTx(data *MetaTxRequest) (*MetaTxResponse, error) {
 	}
 }
 
-func (b *Bcnmy) RawTransact(signer *Signer, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
-	apiId, ok := b.apiID[fmt.Sprintf("%s-%s", b.address.Hex(), method)]
-	if !ok {
-		err := fmt.Errorf("ApiId %s not found for %s", apiId.ID, method)
-		b.logger.Error(err.Error())
-		return nil, nil, nil, err
+// TransactOpts tunes a single RawTransact or EnhanceTransact call, nil
+// selects the defaults.
+type TransactOpts struct {
+	// SignatureEIP712Type (default) or SignaturePersonalType
+	SignatureType string
+	// Simulate runs SimulateWithOpts before relaying and returns its
+	// *SimulationError instead of relaying a request that would revert
+	Simulate bool
+	// SimulationFrom is the caller of the simulation, DefaultSimulationRelayer when zero
+	SimulationFrom common.Address
+	// Confirmations the blocking calls wait for, 1 (mined) when zero
+	Confirmations uint64
+	// PollInterval of the blocking calls' wait, see WaitOpts
+	PollInterval time.Duration
+	// OnStatus is called with every status transition of the Submission
+	OnStatus func(StatusUpdate)
+	// FeeToken makes RawTransact pay the relayer fee in this ERC20 through
+	// the fee proxy set with WithERC20FeeProxy, the DAPP pays when zero
+	FeeToken common.Address
+	// TokenSpend is the amount of the fee token the forwarded call itself
+	// transfers, the balance must cover it on top of the fee
+	TokenSpend *big.Int
+	// Relayers overrides the client's relayers, see WithRelayers
+	Relayers []Relayer
+	// GasStrategy overrides the client's, see WithGasStrategy
+	GasStrategy *GasStrategy
+	// Deadline of the signed forward request, the DeadlinePolicy TTL from now when zero
+	Deadline time.Time
+}
+
+func (o *TransactOpts) signatureType() string {
+	if o == nil || o.SignatureType == "" {
+		return SignatureEIP712Type
+	}
+	return o.SignatureType
+}
+
+func (o *TransactOpts) waitOpts() *WaitOpts {
+	if o == nil {
+		return nil
+	}
+	return &WaitOpts{Confirmations: o.Confirmations, PollInterval: o.PollInterval}
+}
+
+func (o *TransactOpts) feeToken() common.Address {
+	if o == nil {
+		return common.Address{}
+	}
+	return o.FeeToken
+}
+
+func (o *TransactOpts) tokenSpend() *big.Int {
+	if o == nil {
+		return nil
 	}
-	funcSig, err := b.abi.Pack(method, params...)
+	return o.TokenSpend
+}
+
+func (o *TransactOpts) simulate() bool {
+	return o != nil && o.Simulate
+}
+
+func (o *TransactOpts) simulationFrom() common.Address {
+	if o == nil || o.SimulationFrom == (common.Address{}) {
+		return DefaultSimulationRelayer
+	}
+	return o.SimulationFrom
+}
+
+// preflight runs the optional simulation of a signed request.
+func (b *Bcnmy) preflight(ctx context.Context, opts *TransactOpts, metaTxMessage *MetaTxMessage, signature []byte) error {
+	if !opts.simulate() {
+		return nil
+	}
+	result, err := b.SimulateWithOpts(ctx, opts, metaTxMessage, signature)
 	if err != nil {
-		b.logger.WithError(err).Error("Abi Pack failed")
-		return nil, nil, nil, err
+		return err
+	}
+	if err := result.Err(); err != nil {
+		b.logger.WithError(err).Error("Simulate MetaTxMessage failed")
+		return err
 	}
+	return nil
+}
+
+func (b *Bcnmy) RawTransact(signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return b.RawTransactContext(b.ctx, signer, method, params...)
+}
+
+func (b *Bcnmy) RawTransactContext(ctx context.Context, signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return b.RawTransactWithOpts(ctx, nil, signer, method, params...)
+}
+
+// RawTransactWithOpts signs with the mode chosen in opts. SignaturePersonalType
+// requires signer to also implement PersonalSigner.
+func (b *Bcnmy) RawTransactWithOpts(ctx context.Context, opts *TransactOpts, signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return b.defaultDapp().RawTransactWithOpts(ctx, opts, signer, method, params...)
+}
 
-	callMsg := ethereum.CallMsg{
-		From: signer.Address,
-		To:   &b.address,
-		Data: funcSig,
+func (d *DappHandle) RawTransactWithOpts(ctx context.Context, opts *TransactOpts, signer TypedDataSigner, method string, params ...interface{}) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	sub, err := d.SubmitWithOpts(ctx, opts, signer, method, params...)
+	if err != nil {
+		return sub.response(), nil, nil, err
 	}
-	callOpts := bind.CallOpts{
-		Context: b.ctx,
-		From:    signer.Address,
+	tx, receipt, err := sub.WaitWithOpts(ctx, opts.waitOpts())
+	return sub.Response, tx, receipt, err
+}
+
+func (b *Bcnmy) Submit(signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	return b.SubmitContext(b.ctx, signer, method, params...)
+}
+
+func (b *Bcnmy) SubmitContext(ctx context.Context, signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	return b.SubmitWithOpts(ctx, nil, signer, method, params...)
+}
+
+// SubmitWithOpts is the non-blocking RawTransactWithOpts: it returns once
+// Biconomy accepted the request. When Biconomy rejects it the Submission is
+// returned too, carrying the Response.
+func (b *Bcnmy) SubmitWithOpts(ctx context.Context, opts *TransactOpts, signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	return b.defaultDapp().SubmitWithOpts(ctx, opts, signer, method, params...)
+}
+
+func (d *DappHandle) SubmitWithOpts(ctx context.Context, opts *TransactOpts, signer TypedDataSigner, method string, params ...interface{}) (*Submission, error) {
+	b := d.b
+	built, err := d.buildMessage(ctx, opts, signer.GetAddress(), method, params...)
+	if err != nil {
+		return nil, err
+	}
+	metaTxMessage, nonce := built.message, built.nonce
+
+	var req *MetaTxRequest
+	var signature []byte
+	switch opts.signatureType() {
+	case SignatureEIP712Type:
+		req, signature, err = b.signEIP712Request(signer, built.apiID, metaTxMessage)
+	case SignaturePersonalType:
+		req, signature, err = b.signPersonalRequest(signer, built.apiID, metaTxMessage)
+	default:
+		err = fmt.Errorf("Signature type not supported: %s", opts.signatureType())
+	}
+	if err != nil {
+		nonce.Fail()
+		b.logger.WithError(err).Error("Sign MetaTxMessage failed")
+		return nil, err
+	}
+	if err := b.preflight(ctx, opts, metaTxMessage, signature); err != nil {
+		nonce.Fail()
+		return nil, err
+	}
+
+	b.logger.Debugf("MetaTxRequest: %s", ConvertToJsonStr(req))
+	b.logger.Debugf("MetaTxMessage: %s", ConvertToJsonStr(metaTxMessage))
+
+	sub, err := b.relay(ctx, opts, req, metaTxMessage, nonce)
+	if sub != nil {
+		sub.Fee = built.quote
+	}
+	return sub, err
+}
+
+// builtMessage is an unsigned forward request and what was reserved for it.
+type builtMessage struct {
+	message *MetaTxMessage
+	method  *abi.Method
+	apiID   string
+	quote   *FeeQuote
+	nonce   *NonceReservation
+}
+
+// buildMessage prepares the forward request of from calling method with
+// prepareMessage and reserves its nonce.
+func (d *DappHandle) buildMessage(ctx context.Context, opts *TransactOpts, from common.Address, method string, params ...interface{}) (*builtMessage, error) {
+	built, err := d.prepareMessage(ctx, opts, from, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := d.b.ReserveNonce(ctx, from)
+	if err != nil {
+		return nil, err
 	}
-	estimateGas, err := b.ethClient.EstimateGas(b.ctx, callMsg)
+	built.message.BatchId, built.message.BatchNonce = nonce.BatchId, nonce.BatchNonce
+	built.nonce = nonce
+	return built, nil
+}
+
+// prepareMessage prepares the forward request of from calling method:
+// apiId, TxGas, optional ERC20 fee and deadline, leaving the nonce unset.
+func (d *DappHandle) prepareMessage(ctx context.Context, opts *TransactOpts, from common.Address, method string, params ...interface{}) (*builtMessage, error) {
+	b := d.b
+	if err := b.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.ensureTrusted(ctx); err != nil {
+		return nil, err
+	}
+	m, err := d.Method(method, params...)
+	if err != nil {
+		b.logger.WithError(err).Error("Resolve method failed")
+		return nil, err
+	}
+	apiId, err := d.resolveMethodAPIID(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	funcSig, err := packMethod(m, params...)
 	if err != nil {
-		b.logger.WithError(err).Error("EstimateGas failed")
-		return nil, nil, nil, err
+		b.logger.WithError(err).Error("Abi Pack failed")
+		return nil, err
+	}
+
+	estimateGas, err := d.txGas(ctx, opts.gasStrategy(b), from, m, funcSig)
+	if err != nil {
+		return nil, err
 	}
-	batchNonce, err := b.trustedForwarder.Contract.GetNonce(&callOpts, signer.Address, b.batchId)
+	feeToken, tokenGasPrice := common.HexToAddress("0x0"), "0"
+	var quote *FeeQuote
+	if opts.feeToken() != (common.Address{}) {
+		quote, err = b.payFee(ctx, opts, from, opts.feeToken(), estimateGas, nil)
+		if err != nil {
+			return nil, err
+		}
+		feeToken, tokenGasPrice = quote.Token, quote.TokenGasPrice.String()
+	}
+	deadline, err := b.deadline(opts)
 	if err != nil {
-		b.logger.WithError(err).Errorf("GetNonce from %s failed", b.batchId)
-		return nil, nil, nil, err
+		b.logger.WithError(err).Error("Deadline invalid")
+		return nil, err
 	}
 
-	metaTxMessage := &MetaTxMessage{
-		From:          signer.Address,
-		To:            b.address,
-		Token:         common.HexToAddress("0x0"),
-		TxGas:         estimateGas,
-		TokenGasPrice: "0",
-		BatchId:       b.batchId,
-		BatchNonce:    batchNonce,
-		Deadline:      big.NewInt(time.Now().Add(time.Hour).Unix()),
-		Data:          hexutil.Encode(funcSig),
+	return &builtMessage{
+		message: &MetaTxMessage{
+			From:          from,
+			To:            d.address,
+			Token:         feeToken,
+			TxGas:         estimateGas,
+			TokenGasPrice: tokenGasPrice,
+			Deadline:      deadline,
+			Data:          hexutil.Encode(funcSig),
+		},
+		method: m,
+		apiID:  apiId.ID,
+		quote:  quote,
+	}, nil
+}
+
+// relay hands req to the relayers in priority order and returns the
+// Submission tracking it. relay owns nonce, when given: it is handed out
+// again only when no relayer can have sent the request, and is otherwise
+// ended by the Submission once the transaction is final, or at the request
+// deadline if nobody waits for it.
+func (b *Bcnmy) relay(ctx context.Context, opts *TransactOpts, req *MetaTxRequest, metaTxMessage *MetaTxMessage, nonce *NonceReservation) (*Submission, error) {
+	sub := newSubmission(b, opts, req, metaTxMessage)
+	sub.nonce = nonce
+	resp, err := b.relayFailover(ctx, opts, sub, req, metaTxMessage)
+	if err != nil {
+		b.logger.Errorf("Transaction failed: %v", err)
+		if resp == nil {
+			if relayNotSent(err) {
+				nonce.Fail()
+			} else {
+				b.logger.Warnf("Relay outcome unknown, the nonce of %s is not reused", metaTxMessage.From.Hex())
+				nonce.Stale()
+			}
+			return nil, err
+		}
+		sub.Response = resp
+		sub.publish(TxStatusFailed, nil, err)
+		return sub, err
 	}
+	sub.Response = resp
+	sub.TxHash = resp.TxHash
+	nonce.expireAt(b.nonceExpiry(metaTxMessage.Deadline))
+	sub.publish(TxStatusRelayed, nil, nil)
+	return sub, nil
+}
 
-	typedData := apitypes.TypedData{
+// forwardTypedData is the EIP-712 payload of metaTxMessage for the trusted forwarder.
+func (b *Bcnmy) forwardTypedData(metaTxMessage *MetaTxMessage) apitypes.TypedData {
+	return apitypes.TypedData{
 		Types:       SignedTypes,
 		PrimaryType: ForwardRequestType,
 		Domain: apitypes.TypedDataDomain{
@@ -159,83 +424,77 @@ func (b *Bcnmy) RawTransact(signer *Signer, method string, params ...interface{}
 		},
 		Message: metaTxMessage.TypedData(),
 	}
+}
+
+func (b *Bcnmy) signEIP712Request(signer TypedDataSigner, apiId string, metaTxMessage *MetaTxMessage) (*MetaTxRequest, []byte, error) {
+	typedData := b.forwardTypedData(metaTxMessage)
 	signature, err := signer.SignTypedData(typedData)
 	if err != nil {
 		b.logger.WithError(err).Error("Signer signTypeData failed")
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	domainSeparator, err := typedData.HashStruct(EIP712DomainType, typedData.Domain.Map())
 	if err != nil {
 		b.logger.WithError(err).Error("EIP712Domain Separator hash failed")
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
-	req := &MetaTxRequest{
-		From:  signer.Address.Hex(),
-		To:    b.address.Hex(),
-		ApiID: apiId.ID,
+	return &MetaTxRequest{
+		From:  metaTxMessage.From.Hex(),
+		To:    metaTxMessage.To.Hex(),
+		ApiID: apiId,
 		Params: []interface{}{
 			metaTxMessage,
 			hexutil.Encode(domainSeparator),
 			hexutil.Encode(signature),
 		},
 		SignatureType: SignatureEIP712Type,
-	}
-
-	b.logger.Debugf("MetaTxRequest: %s", ConvertToJsonStr(req))
-	b.logger.Debugf("MetaTxMessage: %s", ConvertToJsonStr(metaTxMessage))
+	}, signature, nil
+}
 
-	resp, err := b.SendMetaNativeTx(req)
-	if err != nil {
-		b.logger.Errorf("Transaction failed: %v", err)
-		return resp, nil, nil, err
+func (b *Bcnmy) signPersonalRequest(signer TypedDataSigner, apiId string, metaTxMessage *MetaTxMessage) (*MetaTxRequest, []byte, error) {
+	personalSigner, ok := signer.(PersonalSigner)
+	if !ok {
+		return nil, nil, fmt.Errorf("Signer %s does not support personal sign", signer.GetAddress().Hex())
 	}
-
-	receipt, err := WaitMined(context.Background(), b.ethClient, resp.TxHash)
+	hash, err := PersonalSignHash(metaTxMessage)
 	if err != nil {
-		b.logger.Errorf("WaitMined failed: %v", err)
-		return resp, nil, nil, err
+		return nil, nil, err
 	}
-
-	var tx *types.Transaction
-	retries := 5
-	for {
-		var err error
-		retries -= 1
-		tx, _, err = b.ethClient.TransactionByHash(b.ctx, resp.TxHash)
-		if err != nil {
-			b.logger.Errorf("Checking TransactionByHash failed: %v, retries: %v", err, retries)
-			time.Sleep(time.Second * b.sleepTimeSec)
-		} else {
-			break
-		}
-		if retries < 0 && err != nil {
-			return resp, nil, nil, err
-		}
+	signature, err := personalSigner.SignPersonal(hash.Bytes())
+	if err != nil {
+		b.logger.WithError(err).Error("Signer signPersonal failed")
+		return nil, nil, err
 	}
-	return resp, tx, receipt, err
+	return &MetaTxRequest{
+		From:  metaTxMessage.From.Hex(),
+		To:    metaTxMessage.To.Hex(),
+		ApiID: apiId,
+		Params: []interface{}{
+			metaTxMessage,
+			hexutil.Encode(signature),
+		},
+		SignatureType: SignaturePersonalType,
+	}, signature, nil
 }
 
 func (b *Bcnmy) BuildTransactParams(metaTxMessage *MetaTxMessage, typedDataHash string) ([]byte, error) {
-	typedData := apitypes.TypedData{
-		Types:       SignedTypes,
-		PrimaryType: ForwardRequestType,
-		Domain: apitypes.TypedDataDomain{
-			Name:              ForwardRequestName,
-			Version:           Version,
-			VerifyingContract: b.trustedForwarder.Address.Hex(),
-			Salt:              hexutil.Encode(common.LeftPadBytes(b.chainId.Bytes(), 32)),
-		},
-		Message: metaTxMessage.TypedData(),
+	return b.BuildTransactParamsContext(b.ctx, metaTxMessage, typedDataHash)
+}
+
+func (b *Bcnmy) BuildTransactParamsContext(ctx context.Context, metaTxMessage *MetaTxMessage, typedDataHash string) ([]byte, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
 	}
+	typedData := b.forwardTypedData(metaTxMessage)
 	hash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
 	if err != nil {
 		b.logger.Errorf("HashStruct failed to hash typedData, %v", err)
 		return nil, err
 	}
 	if hash.String() != typedDataHash {
-		err := fmt.Errorf("Hash string not match parameter hash: %s typedDataHash %s", hash.String(), typedDataHash)
+		err := fmt.Errorf("%w: hash string not match parameter hash: %s typedDataHash %s", ErrSignatureInvalid, hash.String(), typedDataHash)
 		b.logger.Errorf("%v", err)
 		return nil, err
 	}
@@ -246,64 +505,155 @@ func (b *Bcnmy) BuildTransactParams(metaTxMessage *MetaTxMessage, typedDataHash
 // / Backend using this method, handle frontend passing signature, MetaTxMessage and
 // / ForwardRequestType data Hash value
 func (b *Bcnmy) EnhanceTransact(from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
-	apiId, ok := b.apiID[fmt.Sprintf("%s-%s", b.address.Hex(), method)]
-	if !ok {
-		err := fmt.Errorf("ApiId %s not found for %s", apiId.ID, method)
-		b.logger.Error(err.Error())
-		return nil, nil, nil, err
-	}
-	domainSeparator, err := b.BuildTransactParams(metaTxMessage, typedDataHash)
-	if err != nil {
-		b.logger.WithError(err).Error("EIP712Domain Separator hash failed")
-		return nil, nil, nil, err
-	}
-	req := &MetaTxRequest{
-		From:  from,
-		To:    b.address.Hex(),
-		ApiID: apiId.ID,
-		Params: []interface{}{
-			metaTxMessage,
-			hexutil.Encode(domainSeparator),
-			hexutil.Encode(signature),
-		},
-		SignatureType: SignatureEIP712Type,
-	}
-	resp, err := b.SendMetaNativeTx(req)
+	return b.EnhanceTransactContext(b.ctx, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (b *Bcnmy) EnhanceTransactContext(ctx context.Context, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return b.EnhanceTransactWithOpts(ctx, nil, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+// EnhanceTransactWithOpts relays a frontend signature of the mode chosen in
+// opts. For SignaturePersonalType typedDataHash is the PersonalSignHash the
+// wallet signed with personal_sign.
+func (b *Bcnmy) EnhanceTransactWithOpts(ctx context.Context, opts *TransactOpts, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	return b.defaultDapp().EnhanceTransactWithOpts(ctx, opts, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) EnhanceTransactWithOpts(ctx context.Context, opts *TransactOpts, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*MetaTxResponse, *types.Transaction, *types.Receipt, error) {
+	sub, err := d.SubmitEnhancedWithOpts(ctx, opts, from, method, signature, metaTxMessage, typedDataHash)
 	if err != nil {
-		b.logger.Errorf("Transaction failed: %v", err)
-		return resp, nil, nil, err
+		return sub.response(), nil, nil, err
 	}
+	tx, receipt, err := sub.WaitWithOpts(ctx, opts.waitOpts())
+	return sub.Response, tx, receipt, err
+}
 
-	b.logger.Debugf("MetaTxRequest: %s", ConvertToJsonStr(req))
-	b.logger.Debugf("MetaTxMessage: %s", ConvertToJsonStr(metaTxMessage))
+func (b *Bcnmy) SubmitEnhanced(from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return b.SubmitEnhancedContext(b.ctx, from, method, signature, metaTxMessage, typedDataHash)
+}
 
-	receipt, err := WaitMined(context.Background(), b.ethClient, resp.TxHash)
+func (b *Bcnmy) SubmitEnhancedContext(ctx context.Context, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return b.SubmitEnhancedWithOpts(ctx, nil, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+// SubmitEnhancedWithOpts is the non-blocking EnhanceTransactWithOpts.
+func (b *Bcnmy) SubmitEnhancedWithOpts(ctx context.Context, opts *TransactOpts, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return b.defaultDapp().SubmitEnhancedWithOpts(ctx, opts, from, method, signature, metaTxMessage, typedDataHash)
+}
+
+func (d *DappHandle) SubmitEnhancedWithOpts(ctx context.Context, opts *TransactOpts, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string) (*Submission, error) {
+	return d.submitEnhanced(ctx, opts, from, method, signature, metaTxMessage, typedDataHash, nil)
+}
+
+// submitEnhanced relays a frontend signed request. It owns nonce, when
+// claimed by SubmitSigned: the nonce is given back when the request is not
+// relayed, and otherwise ended by relay.
+func (d *DappHandle) submitEnhanced(ctx context.Context, opts *TransactOpts, from string, method string, signature []byte, metaTxMessage *MetaTxMessage, typedDataHash string, nonce *NonceReservation) (*Submission, error) {
+	b := d.b
+	relayed := false
+	defer func() {
+		if !relayed {
+			nonce.Fail()
+		}
+	}()
+	if err := b.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.ensureTrusted(ctx); err != nil {
+		return nil, err
+	}
+	apiId, err := d.resolveDataAPIID(ctx, method, metaTxMessage.Data)
 	if err != nil {
-		b.logger.Errorf("WaitMined failed: %v", err)
-		return resp, nil, nil, err
+		return nil, err
 	}
-
-	var tx *types.Transaction
-	retries := 5
-	for {
-		var err error
-		retries -= 1
-		tx, _, err = b.ethClient.TransactionByHash(b.ctx, resp.TxHash)
+	if err := d.verifyMetaTx(opts, from, signature, metaTxMessage); err != nil {
+		b.logger.WithError(err).Error("Verify MetaTxMessage failed")
+		return nil, err
+	}
+	var quote *FeeQuote
+	if metaTxMessage.Token != (common.Address{}) {
+		tokenGasPrice, ok := new(big.Int).SetString(metaTxMessage.TokenGasPrice, 10)
+		if !ok {
+			err := fmt.Errorf("TokenGasPrice %q is not a decimal number", metaTxMessage.TokenGasPrice)
+			b.logger.Error(err.Error())
+			return nil, err
+		}
+		quote, err = b.payFee(ctx, opts, metaTxMessage.From, metaTxMessage.Token, metaTxMessage.TxGas, tokenGasPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := b.preflight(ctx, opts, metaTxMessage, signature); err != nil {
+		return nil, err
+	}
+	var req *MetaTxRequest
+	switch opts.signatureType() {
+	case SignatureEIP712Type:
+		domainSeparator, err := b.BuildTransactParamsContext(ctx, metaTxMessage, typedDataHash)
+		if err != nil {
+			b.logger.WithError(err).Error("EIP712Domain Separator hash failed")
+			return nil, err
+		}
+		req = &MetaTxRequest{
+			From:  from,
+			To:    d.address.Hex(),
+			ApiID: apiId.ID,
+			Params: []interface{}{
+				metaTxMessage,
+				hexutil.Encode(domainSeparator),
+				hexutil.Encode(signature),
+			},
+			SignatureType: SignatureEIP712Type,
+		}
+	case SignaturePersonalType:
+		hash, err := PersonalSignHash(metaTxMessage)
 		if err != nil {
-			b.logger.Errorf("Checking TransactionByHash failed: %v, retries: %v", err, retries)
-			time.Sleep(time.Second * b.sleepTimeSec)
-		} else {
-			break
+			b.logger.WithError(err).Error("PersonalSignHash failed")
+			return nil, err
 		}
-		if retries < 0 && err != nil {
-			return resp, nil, nil, err
+		if hash.String() != typedDataHash {
+			err := fmt.Errorf("%w: hash string not match parameter hash: %s typedDataHash %s", ErrSignatureInvalid, hash.String(), typedDataHash)
+			b.logger.Errorf("%v", err)
+			return nil, err
 		}
+		req = &MetaTxRequest{
+			From:  from,
+			To:    d.address.Hex(),
+			ApiID: apiId.ID,
+			Params: []interface{}{
+				metaTxMessage,
+				hexutil.Encode(signature),
+			},
+			SignatureType: SignaturePersonalType,
+		}
+	default:
+		err := fmt.Errorf("Signature type not supported: %s", opts.signatureType())
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	b.logger.Debugf("MetaTxRequest: %s", ConvertToJsonStr(req))
+	b.logger.Debugf("MetaTxMessage: %s", ConvertToJsonStr(metaTxMessage))
+
+	relayed = true
+	sub, err := b.relay(ctx, opts, req, metaTxMessage, nonce)
+	if sub != nil {
+		sub.Fee = quote
 	}
-	return resp, tx, receipt, err
+	return sub, err
 }
 
 func (b *Bcnmy) Pack(method string, params ...interface{}) ([]byte, error) {
-	data, err := b.abi.Pack(method, params...)
+	return b.defaultDapp().Pack(method, params...)
+}
+
+func (d *DappHandle) Pack(method string, params ...interface{}) ([]byte, error) {
+	b := d.b
+	m, err := d.Method(method, params...)
+	if err != nil {
+		b.logger.WithError(err).Error("Resolve method failed")
+		return nil, err
+	}
+	data, err := packMethod(m, params...)
 	if err != nil {
 		b.logger.WithError(err).Error("Abi Pack failed")
 		return nil, err
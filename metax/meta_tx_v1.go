@@ -2,6 +2,7 @@ package metax
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
@@ -34,6 +35,10 @@ type BiconomyTransaction struct {
 }
 
 func (b *Bcnmy) SendMetaNativeTxV1(data *MetaTxRequest) (*MetaTxResponse, error) {
+	return b.SendMetaNativeTxV1Context(b.ctx, data)
+}
+
+func (b *Bcnmy) SendMetaNativeTxV1Context(ctx context.Context, data *MetaTxRequest) (*MetaTxResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -44,7 +49,7 @@ func (b *Bcnmy) SendMetaNativeTxV1(data *MetaTxRequest) (*MetaTxResponse, error)
 		b.logger.WithError(err).Error("json marshal `MetaTxRequest` data failed")
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, MetaTxNativeURLV1, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.GaslessMetaURL(MetaTxNativePathV1), bytes.NewBuffer(body))
 	if err != nil {
 		b.logger.Error("SendMetaNativeTxV1 NewRequest failed")
 		return nil, err
@@ -61,40 +66,40 @@ func (b *Bcnmy) SendMetaNativeTxV1(data *MetaTxRequest) (*MetaTxResponse, error)
 		if err != nil {
 			return nil, fmt.Errorf("SendMetaNativeTxV1 unmarshal failed, %v", err)
 		}
-		if resp.Flag == 200 {
-			dataBytes, err := json.Marshal(resp.Data)
-			if err != nil {
-				return nil, fmt.Errorf("Error marshaling response data:", err)
+		if resp.Flag != 200 {
+			failed := &MetaTxResponse{
+				Flag:    resp.Flag,
+				TxHash:  common.HexToHash("0x0"),
+				Message: resp.Msg,
+				Code:    resp.Flag,
 			}
-			if err = json.Unmarshal(dataBytes, &successData); err != nil {
-				return nil, fmt.Errorf("Error unmarshaling response data:", err)
-			}
-			break
-		} else if resp.Flag == 400 {
 			switch data := resp.Data.(type) {
 			case string:
-				return &MetaTxResponse{
-					Flag:    resp.Flag,
-					TxHash:  common.HexToHash("0x0"),
-					Error:   data,
-					Message: data,
-					Code:    resp.Flag,
-				}, nil
+				failed.Error = data
+				failed.Message = data
 			case map[string]interface{}:
-				return &MetaTxResponse{
-					Flag:    resp.Flag,
-					TxHash:  common.HexToHash("0x0"),
-					Error:   data["error"].(string),
-					Message: data["error"].(string),
-					Code:    data["code"].(int),
-				}, nil
+				if errMsg, ok := data["error"].(string); ok {
+					failed.Error = errMsg
+					failed.Message = errMsg
+				}
+				if code, ok := data["code"].(float64); ok {
+					failed.Code = int(code)
+				}
 			}
+			return failed, failed.Err()
+		}
+		dataBytes, err := json.Marshal(resp.Data)
+		if err != nil {
+			return nil, fmt.Errorf("Error marshaling response data: %v", err)
+		}
+		if err = json.Unmarshal(dataBytes, &successData); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling response data: %v", err)
 		}
 	case err := <-errorCh:
 		b.logger.WithError(err).Error(err.Error())
 		return nil, err
 	}
-	bcnmyTxn, err := b.GetTransactionStatus(successData.TransactionId)
+	bcnmyTxn, err := b.GetTransactionStatusContext(ctx, successData.TransactionId)
 	if err != nil {
 		b.logger.Error("SendTransactionStatus failed, check error logs")
 		return nil, err
@@ -108,14 +113,43 @@ func (b *Bcnmy) SendMetaNativeTxV1(data *MetaTxRequest) (*MetaTxResponse, error)
 }
 
 func (b *Bcnmy) GetTransactionStatus(transactionId string) (*BiconomyTransaction, error) {
-	bodyCh := make(chan []byte, 1)
+	return b.GetTransactionStatusContext(b.ctx, transactionId)
+}
+
+// GetTransactionStatusContext polls the v1 transaction-status API until the
+// relayed transaction has a hash, up to TransactionStatusPolls times.
+func (b *Bcnmy) GetTransactionStatusContext(ctx context.Context, transactionId string) (*BiconomyTransaction, error) {
+	for poll := 1; poll <= TransactionStatusPolls; poll++ {
+		resp, err := b.fetchTransactionStatus(ctx, transactionId)
+		switch {
+		case err != nil:
+			b.logger.Error(err.Error())
+		case resp.Code != 200:
+			b.logger.Infof("BiconomyTransaction %v Code is empty", resp)
+		case resp.Data.Receipt.TxHash == "" || resp.Data.Receipt.TxHash == common.HexToHash("0x0").Hex():
+			b.logger.Infof("BiconomyTransaction %v txHash is empty", resp)
+		default:
+			return resp, nil
+		}
+		if poll == TransactionStatusPolls {
+			break
+		}
+		if err := sleepContext(ctx, b.sleepTimeSec*time.Second); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("GetBiconomyTransactionStatus %s reach maxiumum retries", transactionId)
+}
+
+func (b *Bcnmy) fetchTransactionStatus(ctx context.Context, transactionId string) (*BiconomyTransaction, error) {
+	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
 	defer close(errorCh)
 	queryParams := url.Values{}
 	queryParams.Set("transactionId", transactionId)
-	urlWithParams := MetaTransactionStatusURL + "?" + queryParams.Encode()
-	req, err := http.NewRequest(http.MethodGet, urlWithParams, nil)
+	urlWithParams := b.endpoints.GaslessMetaURL(MetaTransactionStatusPath) + "?" + queryParams.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlWithParams, nil)
 	if err != nil {
 		b.logger.WithError(err).Error("SendTransactionStatus NewRequest failed")
 		return nil, err
@@ -125,34 +159,14 @@ func (b *Bcnmy) GetTransactionStatus(transactionId string) (*BiconomyTransaction
 	req.Header.Set("version", PACKAGE_VERSION)
 	var resp BiconomyTransaction
 	b.asyncHttpx(req, errorCh, bodyCh)
-	retries := 5
-LOOP:
-	for {
-		retries -= 1
-		if retries <= 0 {
-			return nil, fmt.Errorf("GetBiconomyTransactionStatus %s reach maxiumum retries", transactionId)
-		}
-		select {
-		case ret := <-bodyCh:
-			err = json.Unmarshal(ret, &resp)
-			if err != nil {
-				return nil, fmt.Errorf("GetTransactionStatus unmarshal failed, %v", err)
-			}
-			if resp.Code != 200 {
-				b.logger.Infof("BiconomyTransaction %v Code is empty", resp)
-				time.Sleep(b.sleepTimeSec * time.Second)
-				continue LOOP
-			}
-			if resp.Data.Receipt.TxHash == "" || resp.Data.Receipt.TxHash == common.HexToHash("0x0").Hex() {
-				b.logger.Infof("BiconomyTransaction %v txHash is empty", resp)
-				time.Sleep(b.sleepTimeSec * time.Second)
-				continue LOOP
-			}
-			return &resp, nil
-		case err := <-errorCh:
-			b.logger.Error(err.Error())
-			time.Sleep(b.sleepTimeSec * time.Second)
-			continue LOOP
+	select {
+	case ret := <-bodyCh:
+		err = json.Unmarshal(ret, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("GetTransactionStatus unmarshal failed, %v", err)
 		}
+		return &resp, nil
+	case err := <-errorCh:
+		return nil, err
 	}
 }
@@ -0,0 +1,186 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// normalizeMethod strips the spaces of a signature and lowercases a selector,
+// leaving plain names as they are.
+func normalizeMethod(method string) string {
+	method = strings.TrimSpace(method)
+	if isSelector(method) {
+		return strings.ToLower(method)
+	}
+	if strings.Contains(method, "(") {
+		return strings.Join(strings.Fields(method), "")
+	}
+	return method
+}
+
+func isSelector(method string) bool {
+	if len(method) != 10 || !strings.HasPrefix(method, "0x") {
+		return false
+	}
+	_, err := hexutil.Decode(method)
+	return err == nil
+}
+
+// methodSelector is the 4-byte selector of a canonical signature, empty for
+// plain names and selectors.
+func methodSelector(method string) string {
+	method = normalizeMethod(method)
+	if !strings.Contains(method, "(") {
+		return ""
+	}
+	return hexutil.Encode(crypto.Keccak256([]byte(method))[:4])
+}
+
+// apiIDMethodKeys are the keys a dashboard method is stored under: as written
+// and, for a signature, by its selector.
+func apiIDMethodKeys(method string) []string {
+	keys := []string{normalizeMethod(method)}
+	if selector := methodSelector(method); selector != "" {
+		keys = append(keys, selector)
+	}
+	return keys
+}
+
+// methodAPIKeys are the apiId keys tried for m, most specific first. Entries
+// registered by plain name are shared by all overloads of that name.
+func methodAPIKeys(m *abi.Method) []string {
+	keys := []string{m.Sig, hexutil.Encode(m.ID)}
+	if m.Name != m.RawName {
+		keys = append(keys, m.Name)
+	}
+	return append(keys, m.RawName)
+}
+
+// Method resolves method on the DAPP ABI. It may be a name, the go-ethereum
+// key of an overload ("safeTransferFrom0"), a canonical signature
+// ("safeTransferFrom(address,address,uint256)") or a 4-byte selector
+// ("0x42842e0e"). A name shared by overloads is resolved from params: the
+// overload whose inputs accept them is picked.
+func (d *DappHandle) Method(method string, params ...interface{}) (*abi.Method, error) {
+	return d.resolveMethod(method, params, true)
+}
+
+func (d *DappHandle) resolveMethod(method string, params []interface{}, withParams bool) (*abi.Method, error) {
+	method = normalizeMethod(method)
+	if isSelector(method) {
+		selector, _ := hexutil.Decode(method)
+		m, err := d.abi.MethodById(selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMethodNotFound, method)
+		}
+		return m, nil
+	}
+	if strings.Contains(method, "(") {
+		for _, m := range d.abi.Methods {
+			if m.Sig == method {
+				m := m
+				return &m, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrMethodNotFound, method)
+	}
+
+	var overloads []abi.Method
+	for _, m := range d.abi.Methods {
+		if m.RawName == method {
+			overloads = append(overloads, m)
+		}
+	}
+	sort.Slice(overloads, func(i, j int) bool { return overloads[i].Name < overloads[j].Name })
+	switch {
+	case len(overloads) == 1:
+		return &overloads[0], nil
+	case len(overloads) > 1:
+		if !withParams {
+			return nil, fmt.Errorf("%w: %s, use the signature or selector", ErrAmbiguousMethod, method)
+		}
+		return pickOverload(method, overloads, params)
+	}
+	if m, ok := d.abi.Methods[method]; ok {
+		return &m, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMethodNotFound, method)
+}
+
+// pickOverload returns the only overload whose inputs pack params.
+func pickOverload(method string, overloads []abi.Method, params []interface{}) (*abi.Method, error) {
+	var matches []abi.Method
+	for _, m := range overloads {
+		if len(m.Inputs) != len(params) {
+			continue
+		}
+		if _, err := m.Inputs.Pack(params...); err == nil {
+			matches = append(matches, m)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: no overload of %s accepts %v arguments of these types", ErrMethodNotFound, method, len(params))
+	case 1:
+		return &matches[0], nil
+	}
+	sigs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		sigs = append(sigs, m.Sig)
+	}
+	return nil, fmt.Errorf("%w: %s, use one of %s", ErrAmbiguousMethod, method, strings.Join(sigs, ", "))
+}
+
+// resolveDataAPIID resolves the API entry of a frontend built forward request
+// from the method its data calls, checking it is the method the caller named.
+// Data calling no method of the ABI is refused rather than relayed under the
+// named method's apiId.
+func (d *DappHandle) resolveDataAPIID(ctx context.Context, method string, data string) (MetaAPIInfo, error) {
+	m, err := d.dataMethod(data)
+	if err == nil && method != "" && !methodNames(m, normalizeMethod(method)) {
+		err = fmt.Errorf("%w: data calls %s, not %s", ErrMethodMismatch, m.Sig, method)
+	}
+	if err != nil {
+		d.b.logger.Error(err.Error())
+		return MetaAPIInfo{}, err
+	}
+	return d.resolveMethodAPIID(ctx, m)
+}
+
+// dataMethod is the method of the DAPP ABI data calls, by its selector.
+func (d *DappHandle) dataMethod(data string) (*abi.Method, error) {
+	input, err := hexutil.Decode(data)
+	if err != nil || len(input) < 4 {
+		return nil, fmt.Errorf("%w: data %q has no selector", ErrMethodMismatch, data)
+	}
+	m, err := d.abi.MethodById(input[:4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: data calls %s, not in the dapp ABI", ErrMethodMismatch, hexutil.Encode(input[:4]))
+	}
+	return m, nil
+}
+
+func methodNames(m *abi.Method, method string) bool {
+	return method == m.Name || method == m.RawName || method == m.Sig || method == hexutil.Encode(m.ID)
+}
+
+// resolveMethodAPIID resolves m's Biconomy API entry, preferring one
+// registered for its signature or selector over one for its name.
+func (d *DappHandle) resolveMethodAPIID(ctx context.Context, m *abi.Method) (MetaAPIInfo, error) {
+	return d.b.resolveAPIID(ctx, d.address, methodAPIKeys(m)...)
+}
+
+// packMethod is abi.Pack for a resolved method.
+func packMethod(m *abi.Method, params ...interface{}) ([]byte, error) {
+	args, err := m.Inputs.Pack(params...)
+	if err != nil {
+		return nil, fmt.Errorf("Pack %s failed: %w", m.Sig, err)
+	}
+	return append(append([]byte{}, m.ID...), args...), nil
+}
@@ -0,0 +1,392 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultNonceHold is how long BuildForwardRequest keeps the nonce of a
+// payload for SubmitSigned, see WithNonceHold.
+var DefaultNonceHold = time.Minute
+
+// NonceOrdering selects how concurrent meta transactions of one signer are
+// spread over the forwarder's nonce batches.
+type NonceOrdering int
+
+const (
+	// SequentialNonces keeps every request of a signer on batch 0, so they
+	// are executed in signing order and a failure stalls the following ones.
+	SequentialNonces NonceOrdering = iota
+	// ParallelNonces gives each in-flight request of a signer the least busy
+	// of several batches, so they are executed independently.
+	ParallelNonces
+)
+
+// NonceReservation is a batch nonce handed out to one request.
+type NonceReservation struct {
+	Signer     common.Address
+	BatchId    *big.Int
+	BatchNonce *big.Int
+
+	m     *nonceManager
+	once  sync.Once
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// nonceOutcome is what became of a reserved nonce.
+type nonceOutcome int
+
+const (
+	nonceUsed nonceOutcome = iota
+	nonceUnused
+	nonceUnknown
+)
+
+// Done returns the reservation after its nonce was consumed on chain.
+func (r *NonceReservation) Done() {
+	r.end(nonceUsed)
+}
+
+// Fail returns the reservation of a request that was not executed, so the
+// nonce is handed out again and the batch reconciled with the chain.
+func (r *NonceReservation) Fail() {
+	r.end(nonceUnused)
+}
+
+// Stale returns the reservation of a request that may or may not reach the
+// chain, e.g. after a relay timeout: the nonce is not handed out again and
+// the batch is reconciled with the chain before the next reservation.
+func (r *NonceReservation) Stale() {
+	r.end(nonceUnknown)
+}
+
+func (r *NonceReservation) end(outcome nonceOutcome) {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		r.mu.Lock()
+		if r.timer != nil {
+			r.timer.Stop()
+		}
+		r.mu.Unlock()
+		r.m.release(r, outcome)
+	})
+}
+
+// expireAt ends the reservation as Stale at deadline, when its request can
+// no longer be executed, in case nobody waits for the transaction.
+func (r *NonceReservation) expireAt(deadline time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timer == nil {
+		r.timer = time.AfterFunc(time.Until(deadline), r.Stale)
+	}
+}
+
+// batchNonces is the local view of one (signer, batchId) nonce sequence.
+type batchNonces struct {
+	// requests that picked this batch, guarded by nonceManager.mu
+	inFlight int
+
+	mu       sync.Mutex
+	next     *big.Int   // next nonce never handed out
+	holes    []*big.Int // failed nonces to hand out again, ascending
+	reserved int        // nonces handed out and not yet released
+	stale    bool       // reconcile with the chain before the next reservation
+}
+
+// nonceKey names one nonce of a signer's batch.
+type nonceKey struct {
+	signer  common.Address
+	batchId int64
+	nonce   string
+}
+
+type nonceManager struct {
+	ordering NonceOrdering
+	batches  int64
+
+	mu      sync.Mutex
+	signers map[common.Address]map[int64]*batchNonces
+	// reservations of built payloads waiting for their signature
+	held map[nonceKey]*NonceReservation
+}
+
+func newNonceManager(ordering NonceOrdering, batches int) *nonceManager {
+	if ordering == SequentialNonces || batches < 1 {
+		batches = 1
+	}
+	return &nonceManager{
+		ordering: ordering,
+		batches:  int64(batches),
+		signers:  make(map[common.Address]map[int64]*batchNonces),
+		held:     make(map[nonceKey]*NonceReservation),
+	}
+}
+
+// reserve picks a batch for signer and the next nonce in it. chainNonce
+// reads the forwarder's getNonce and is called whenever the batch is idle or
+// was marked stale by a failure.
+func (m *nonceManager) reserve(ctx context.Context, signer common.Address, chainNonce func(ctx context.Context, batchId *big.Int) (*big.Int, error)) (*NonceReservation, error) {
+	batchId, bn := m.pick(signer)
+
+	bn.mu.Lock()
+	defer bn.mu.Unlock()
+	if bn.next == nil || bn.stale || bn.reserved == 0 {
+		nonce, err := chainNonce(ctx, big.NewInt(batchId))
+		if err != nil {
+			m.mu.Lock()
+			bn.inFlight--
+			m.mu.Unlock()
+			return nil, err
+		}
+		bn.reconcile(nonce)
+	}
+
+	var nonce *big.Int
+	if len(bn.holes) > 0 {
+		nonce, bn.holes = bn.holes[0], bn.holes[1:]
+	} else {
+		nonce = new(big.Int).Set(bn.next)
+		bn.next.Add(bn.next, big.NewInt(1))
+	}
+	bn.reserved++
+	return &NonceReservation{
+		Signer:     signer,
+		BatchId:    big.NewInt(batchId),
+		BatchNonce: nonce,
+		m:          m,
+	}, nil
+}
+
+// pick returns the least busy batch of signer, counting the new request in.
+func (m *nonceManager) pick(signer common.Address) (int64, *batchNonces) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	batches := m.batchesOf(signer)
+	best := m.leastBusy(batches)
+	batches[best].inFlight++
+	return best, batches[best]
+}
+
+// batchesOf returns the batches of signer, creating them. m.mu must be held.
+func (m *nonceManager) batchesOf(signer common.Address) map[int64]*batchNonces {
+	batches, ok := m.signers[signer]
+	if !ok {
+		batches = make(map[int64]*batchNonces)
+		m.signers[signer] = batches
+	}
+	for batchId := int64(0); batchId < m.batches; batchId++ {
+		if _, ok := batches[batchId]; !ok {
+			batches[batchId] = &batchNonces{}
+		}
+	}
+	return batches
+}
+
+// leastBusy is the batch with the fewest requests in flight, the lowest
+// first. Batches not tracked yet are idle. m.mu must be held.
+func (m *nonceManager) leastBusy(batches map[int64]*batchNonces) int64 {
+	inFlight := func(batchId int64) int {
+		if bn, ok := batches[batchId]; ok {
+			return bn.inFlight
+		}
+		return 0
+	}
+	var best int64
+	for batchId := int64(1); batchId < m.batches; batchId++ {
+		if inFlight(batchId) < inFlight(best) {
+			best = batchId
+		}
+	}
+	return best
+}
+
+// peek returns the batch and nonce reserve would hand out to signer now,
+// without reserving them. Signers without requests in flight are not
+// tracked.
+func (m *nonceManager) peek(ctx context.Context, signer common.Address, chainNonce func(ctx context.Context, batchId *big.Int) (*big.Int, error)) (*big.Int, *big.Int, error) {
+	m.mu.Lock()
+	batches := m.signers[signer]
+	batchId := m.leastBusy(batches)
+	bn := batches[batchId]
+	m.mu.Unlock()
+
+	if bn == nil {
+		nonce, err := chainNonce(ctx, big.NewInt(batchId))
+		return big.NewInt(batchId), nonce, err
+	}
+	bn.mu.Lock()
+	defer bn.mu.Unlock()
+	if bn.next == nil || bn.stale || bn.reserved == 0 {
+		nonce, err := chainNonce(ctx, big.NewInt(batchId))
+		if err != nil {
+			return nil, nil, err
+		}
+		bn.reconcile(nonce)
+	}
+	nonce := bn.next
+	if len(bn.holes) > 0 {
+		nonce = bn.holes[0]
+	}
+	return big.NewInt(batchId), new(big.Int).Set(nonce), nil
+}
+
+// hold keeps r for claim until ttl passes, then ends it as Fail so its nonce
+// is handed out again.
+func (m *nonceManager) hold(r *NonceReservation, ttl time.Duration) {
+	key := nonceKey{r.Signer, r.BatchId.Int64(), r.BatchNonce.String()}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.mu.Lock()
+	r.timer = time.AfterFunc(ttl, func() {
+		if m.unhold(key) == r {
+			r.Fail()
+		}
+	})
+	r.mu.Unlock()
+	m.held[key] = r
+}
+
+// unhold takes the held reservation of key, nil when there is none.
+func (m *nonceManager) unhold(key nonceKey) *NonceReservation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.held[key]
+	delete(m.held, key)
+	return r
+}
+
+// claim reserves exactly nonce of batchId for signer: the reservation held
+// for it since the payload was built, or else a nonce peek handed out or
+// whose hold expired. It fails with ErrNonceUnavailable once the nonce was
+// used, reserved by another request, or would leave a gap.
+func (m *nonceManager) claim(ctx context.Context, signer common.Address, batchId, nonce *big.Int, chainNonce func(ctx context.Context, batchId *big.Int) (*big.Int, error)) (*NonceReservation, error) {
+	if batchId.Sign() < 0 || batchId.Cmp(big.NewInt(m.batches)) >= 0 {
+		return nil, fmt.Errorf("%w: batch %v is not in use", ErrNonceUnavailable, batchId)
+	}
+	if r := m.unhold(nonceKey{signer, batchId.Int64(), nonce.String()}); r != nil {
+		// a hold expiring now finds nothing to unhold and leaves r alone
+		r.mu.Lock()
+		r.timer.Stop()
+		r.timer = nil
+		r.mu.Unlock()
+		return r, nil
+	}
+	m.mu.Lock()
+	bn := m.batchesOf(signer)[batchId.Int64()]
+	bn.inFlight++
+	m.mu.Unlock()
+	unclaimed := func() {
+		m.mu.Lock()
+		bn.inFlight--
+		m.mu.Unlock()
+	}
+
+	bn.mu.Lock()
+	defer bn.mu.Unlock()
+	if bn.next == nil || bn.stale || bn.reserved == 0 {
+		current, err := chainNonce(ctx, batchId)
+		if err != nil {
+			unclaimed()
+			return nil, err
+		}
+		bn.reconcile(current)
+	}
+	claimed := false
+	for i, hole := range bn.holes {
+		if hole.Cmp(nonce) == 0 {
+			bn.holes = append(bn.holes[:i], bn.holes[i+1:]...)
+			claimed = true
+			break
+		}
+	}
+	if !claimed && bn.next.Cmp(nonce) == 0 {
+		bn.next.Add(bn.next, big.NewInt(1))
+		claimed = true
+	}
+	if !claimed {
+		unclaimed()
+		return nil, fmt.Errorf("%w: nonce %v of batch %v, next is %v", ErrNonceUnavailable, nonce, batchId, bn.next)
+	}
+	bn.reserved++
+	return &NonceReservation{
+		Signer:     signer,
+		BatchId:    new(big.Int).Set(batchId),
+		BatchNonce: new(big.Int).Set(nonce),
+		m:          m,
+	}, nil
+}
+
+// reconcile aligns the local view with the chain nonce: when nothing is
+// reserved the chain is authoritative, otherwise nonces below it are used up.
+func (bn *batchNonces) reconcile(chainNonce *big.Int) {
+	if bn.reserved == 0 || bn.next == nil {
+		bn.next = new(big.Int).Set(chainNonce)
+		bn.holes = nil
+	} else {
+		holes := bn.holes[:0]
+		for _, hole := range bn.holes {
+			if hole.Cmp(chainNonce) >= 0 {
+				holes = append(holes, hole)
+			}
+		}
+		bn.holes = holes
+		if bn.next.Cmp(chainNonce) < 0 {
+			bn.next = new(big.Int).Set(chainNonce)
+		}
+	}
+	bn.stale = false
+}
+
+func (m *nonceManager) release(r *NonceReservation, outcome nonceOutcome) {
+	m.mu.Lock()
+	bn := m.signers[r.Signer][r.BatchId.Int64()]
+	bn.inFlight--
+	m.mu.Unlock()
+
+	bn.mu.Lock()
+	defer bn.mu.Unlock()
+	bn.reserved--
+	switch outcome {
+	case nonceUnused:
+		bn.holes = append(bn.holes, r.BatchNonce)
+		sort.Slice(bn.holes, func(i, j int) bool { return bn.holes[i].Cmp(bn.holes[j]) < 0 })
+		bn.stale = true
+	case nonceUnknown:
+		bn.stale = true
+	}
+}
+
+func (b *Bcnmy) chainNonce(signer common.Address) func(ctx context.Context, batchId *big.Int) (*big.Int, error) {
+	return func(ctx context.Context, batchId *big.Int) (*big.Int, error) {
+		return b.trustedForwarder.Contract.GetNonce(&bind.CallOpts{Context: ctx, From: signer}, signer, batchId)
+	}
+}
+
+// ReserveNonce hands out the next forwarder batch nonce of signer, taking
+// requests still in flight into account. The reservation must be ended with
+// Done, Fail or Stale; RawTransact and Submit do so themselves.
+func (b *Bcnmy) ReserveNonce(ctx context.Context, signer common.Address) (*NonceReservation, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	reservation, err := b.nonces.reserve(ctx, signer, b.chainNonce(signer))
+	if err != nil {
+		b.logger.WithError(err).Errorf("GetNonce of %s failed", signer.Hex())
+		return nil, fmt.Errorf("GetNonce of %s failed: %w", signer.Hex(), err)
+	}
+	return reservation, nil
+}
@@ -0,0 +1,249 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+// Option configures a Bcnmy built with New.
+type Option func(*Bcnmy) error
+
+// chainIDReader is implemented by *ethclient.Client and the simulated backend.
+type chainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// transactionReader is implemented by *ethclient.Client and the simulated backend.
+type transactionReader interface {
+	TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error)
+}
+
+// WithRPC sets the RPC url dialed on first use when no backend is given.
+func WithRPC(httpRpc string) Option {
+	return func(b *Bcnmy) error {
+		b.httpRpc = httpRpc
+		return nil
+	}
+}
+
+func WithEthClient(client *ethclient.Client) Option {
+	return func(b *Bcnmy) error {
+		if client == nil {
+			return fmt.Errorf("WithEthClient got nil client")
+		}
+		b.ethClient = client
+		return nil
+	}
+}
+
+// WithContractBackend uses any bind.ContractBackend for chain access. Waiting
+// for receipts additionally needs bind.DeployBackend and TransactionByHash.
+func WithContractBackend(backend bind.ContractBackend) Option {
+	return func(b *Bcnmy) error {
+		if backend == nil {
+			return fmt.Errorf("WithContractBackend got nil backend")
+		}
+		b.ethClient = backend
+		return nil
+	}
+}
+
+func WithChainID(chainId *big.Int) Option {
+	return func(b *Bcnmy) error {
+		if chainId == nil || chainId.Sign() <= 0 {
+			return fmt.Errorf("WithChainID got invalid chain ID: %v", chainId)
+		}
+		b.chainId = new(big.Int).Set(chainId)
+		return nil
+	}
+}
+
+// WithForwarderAddress overrides the ForwarderAddressMap entry for the chain.
+func WithForwarderAddress(address common.Address) Option {
+	return func(b *Bcnmy) error {
+		b.trustedForwarder.Address = address
+		return nil
+	}
+}
+
+// WithAPIIDs preloads the method apiId table, e.g. from a cached
+// MetaAPIResponse.ListAPI, so the meta API is not queried on first use.
+// It is still reloaded after WithAPIIDTTL unless the TTL is zero.
+func WithAPIIDs(listAPI []MetaAPIInfo) Option {
+	return func(b *Bcnmy) error {
+		b.setAPIIDLocked(listAPI)
+		b.apiIDLoaded = true
+		b.apiIDLoadedAt = time.Now()
+		return nil
+	}
+}
+
+// WithAPIIDTTL sets how long the apiId table is used before it is reloaded
+// from the meta API, DefaultAPIIDTTL by default. Zero never reloads it on age.
+func WithAPIIDTTL(ttl time.Duration) Option {
+	return func(b *Bcnmy) error {
+		if ttl < 0 {
+			return fmt.Errorf("WithAPIIDTTL got negative TTL: %v", ttl)
+		}
+		b.apiIDTTL = ttl
+		return nil
+	}
+}
+
+// WithNonceOrdering chooses how concurrent requests of one signer get their
+// forwarder nonces; batches is the number of batch IDs ParallelNonces spreads
+// them over.
+func WithNonceOrdering(ordering NonceOrdering, batches int) Option {
+	return func(b *Bcnmy) error {
+		if ordering == ParallelNonces && batches < 2 {
+			return fmt.Errorf("WithNonceOrdering needs at least 2 batches for parallel ordering, got %v", batches)
+		}
+		b.nonces = newNonceManager(ordering, batches)
+		return nil
+	}
+}
+
+// WithNonceHold sets how long BuildForwardRequest holds the nonce of a
+// payload for its SubmitSigned, DefaultNonceHold by default and at most until
+// the request deadline. Zero holds nothing: concurrent builds for one signer
+// then get the same nonce and only the first one submitted is relayed.
+func WithNonceHold(hold time.Duration) Option {
+	return func(b *Bcnmy) error {
+		if hold < 0 {
+			return fmt.Errorf("WithNonceHold needs a non-negative duration, got %v", hold)
+		}
+		b.nonceHold = hold
+		return nil
+	}
+}
+
+// WithGasStrategy sets how RawTransact and Submit choose TxGas,
+// DefaultForwarderGasStrategy by default.
+func WithGasStrategy(strategy GasStrategy) Option {
+	return func(b *Bcnmy) error {
+		if err := strategy.validate(); err != nil {
+			return err
+		}
+		b.gasStrategy = strategy
+		return nil
+	}
+}
+
+// WithDeadlinePolicy sets the deadline RawTransact and Submit sign and the
+// checks of every deadline, including EnhanceTransact's.
+func WithDeadlinePolicy(policy DeadlinePolicy) Option {
+	return func(b *Bcnmy) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		b.deadlinePolicy = policy
+		return nil
+	}
+}
+
+// WithERC20FeeProxy sets the fee proxy of Biconomy's ERC20 forwarder on the
+// chain, which TransactOpts.FeeToken requests are paid through and users
+// approve their fee token to.
+func WithERC20FeeProxy(address common.Address) Option {
+	return func(b *Bcnmy) error {
+		b.feeProxy = address
+		return nil
+	}
+}
+
+// WithSelfRelay sends the requests Biconomy fails to relay, as decided by
+// config.Policy, to the forwarder from config.Signer. It is the failover of
+// the default relayers, see WithRelayers to choose others.
+func WithSelfRelay(config SelfRelayConfig) Option {
+	return func(b *Bcnmy) error {
+		if config.Signer == nil {
+			return fmt.Errorf("WithSelfRelay needs a Signer")
+		}
+		b.selfRelayer = &selfRelayer{b: b, config: config}
+		return nil
+	}
+}
+
+// WithTrustedForwarderCheck sets whether Submit and SubmitEnhanced refuse
+// DAPPs that are not EIP-2771 recipients trusting the chain's forwarder, see
+// DappHandle.CheckTrustedForwarder. It is off by default: the check costs an
+// eth_call per DAPP and fails for recipients without isTrustedForwarder.
+// Turn it on to catch DAPPs that would run the call as the forwarder instead
+// of the signer.
+func WithTrustedForwarderCheck(enabled bool) Option {
+	return func(b *Bcnmy) error {
+		b.checkTrusted = enabled
+		return nil
+	}
+}
+
+func WithEndpoints(endpoints Endpoints) Option {
+	return func(b *Bcnmy) error {
+		b.endpoints = endpoints
+		return nil
+	}
+}
+
+// WithPollInterval sets how often Wait polls for the receipt,
+// DefaultPollInterval by default. WaitOpts.PollInterval overrides it.
+func WithPollInterval(interval time.Duration) Option {
+	return func(b *Bcnmy) error {
+		if interval <= 0 {
+			return fmt.Errorf("WithPollInterval got non-positive interval: %v", interval)
+		}
+		b.pollInterval = interval
+		return nil
+	}
+}
+
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(b *Bcnmy) error {
+		b.httpClient = &http.Client{Timeout: timeout}
+		return nil
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(b *Bcnmy) error {
+		if client == nil {
+			return fmt.Errorf("WithHTTPClient got nil client")
+		}
+		b.httpClient = client
+		return nil
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy for every Biconomy request.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(b *Bcnmy) error {
+		if policy == nil {
+			policy = NoRetry
+		}
+		b.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithRateLimits throttles requests client side, per endpoint group.
+func WithRateLimits(limits RateLimits) Option {
+	return func(b *Bcnmy) error {
+		b.limiter = newRateLimiter(limits)
+		return nil
+	}
+}
+
+func WithLogger(logger *logrus.Entry) Option {
+	return func(b *Bcnmy) error {
+		b.logger = logger
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// PersonalSigner signs data with the EIP-191 personal_sign prefix
+// "\x19Ethereum Signed Message:\n" + len(data). The returned signature is 65
+// bytes with v in {27, 28}.
+type PersonalSigner interface {
+	GetAddress() common.Address
+	SignPersonal(data []byte) ([]byte, error)
+}
+
+// PersonalSignHash is the hash the forwarder's executePersonalSign expects
+// to be personal signed:
+//
+//	keccak256(abi.encodePacked(from, to, token, txGas, tokenGasPrice, batchId, batchNonce, deadline, keccak256(data)))
+func PersonalSignHash(m *MetaTxMessage) (common.Hash, error) {
+	req, err := m.ForwardRequest()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(
+		req.From.Bytes(),
+		req.To.Bytes(),
+		req.Token.Bytes(),
+		math.U256Bytes(req.TxGas),
+		math.U256Bytes(req.TokenGasPrice),
+		math.U256Bytes(new(big.Int).Set(req.BatchId)),
+		math.U256Bytes(new(big.Int).Set(req.BatchNonce)),
+		math.U256Bytes(new(big.Int).Set(req.Deadline)),
+		crypto.Keccak256(req.Data),
+	), nil
+}
+
+func (s *Signer) SignPersonal(data []byte) ([]byte, error) {
+	sig, err := crypto.Sign(accounts.TextHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *KeystoreSigner) SignPersonal(data []byte) ([]byte, error) {
+	sig, err := s.ks.SignHash(s.account, accounts.TextHash(data))
+	if err != nil {
+		return nil, err
+	}
+	if sig[64] == 0 || sig[64] == 1 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+// SignPersonal uses Clef account_signData with the text/plain content type.
+func (s *ExternalSigner) SignPersonal(data []byte) ([]byte, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var sig hexutil.Bytes
+	address := common.NewMixedcaseAddress(s.address)
+	err := s.client.CallContext(ctx, &sig, "account_signData", accounts.MimetypeTextPlain, &address, hexutil.Encode(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("External signer returned %v bytes signature", len(sig))
+	}
+	if sig[64] == 0 || sig[64] == 1 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
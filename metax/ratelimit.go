@@ -0,0 +1,214 @@
+package metax
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointGroup is the class of Biconomy endpoints sharing one rate limit.
+type EndpointGroup string
+
+const (
+	// meta-tx native v1/v2 and the v1 transaction status
+	RelayEndpoints EndpointGroup = "relay"
+	// data host, meta-api and checkLimits
+	DataEndpoints EndpointGroup = "data"
+	// dashboard public api, whitelist and dashboard backend
+	DashboardEndpoints EndpointGroup = "dashboard"
+)
+
+var endpointGroups = map[string]EndpointGroup{
+	MetaTxNativePath:            RelayEndpoints,
+	MetaTxNativePathV1:          RelayEndpoints,
+	MetaTransactionStatusPath:   RelayEndpoints,
+	MetaAPIPath:                 DataEndpoints,
+	CheckLimitPath:              DataEndpoints,
+	UniqueUserDataPath:          DataEndpoints,
+	UserLimitPath:               DataEndpoints,
+	GasTankBalancePath:          DataEndpoints,
+	CreateDappPublicPath:        DashboardEndpoints,
+	AddContractPath:             DashboardEndpoints,
+	AddMethodPath:               DashboardEndpoints,
+	DeleteContractPath:          DashboardEndpoints,
+	DeleteMethodPath:            DashboardEndpoints,
+	AddDestinationAddressesPath: DashboardEndpoints,
+	ProxyContractsPath:          DashboardEndpoints,
+	BackendLoginPath:            DashboardEndpoints,
+	BackendDappPath:             DashboardEndpoints,
+}
+
+// endpointGroupOf classifies a request path. Hosts may carry a path prefix,
+// so the known paths are matched as suffixes.
+func endpointGroupOf(path string) EndpointGroup {
+	if group, ok := endpointGroups[path]; ok {
+		return group
+	}
+	for suffix, group := range endpointGroups {
+		if strings.HasSuffix(path, suffix) {
+			return group
+		}
+	}
+	return DashboardEndpoints
+}
+
+// RateLimit is a token bucket of RequestsPerSecond refilling up to Burst,
+// plus a cap of MaxInFlight concurrent requests. Zero values disable either.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxInFlight       int
+}
+
+type RateLimits struct {
+	Relay     RateLimit
+	Data      RateLimit
+	Dashboard RateLimit
+	// OnWait, when set, is called with the time every request spent waiting
+	OnWait func(group EndpointGroup, wait time.Duration)
+}
+
+// LimiterStats reports how much the client side limiter held requests back.
+type LimiterStats struct {
+	Requests  uint64
+	Waited    uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	InFlight  int
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes one token and returns how long the caller must wait for it.
+func (t *tokenBucket) reserve(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+	t.tokens -= 1
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+func (t *tokenBucket) cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens += 1
+}
+
+type groupLimiter struct {
+	group  EndpointGroup
+	bucket *tokenBucket
+	sem    chan struct{}
+
+	mu    sync.Mutex
+	stats LimiterStats
+}
+
+type rateLimiter struct {
+	groups map[EndpointGroup]*groupLimiter
+	onWait func(group EndpointGroup, wait time.Duration)
+}
+
+func newGroupLimiter(group EndpointGroup, limit RateLimit) *groupLimiter {
+	g := &groupLimiter{group: group}
+	if limit.RequestsPerSecond > 0 {
+		burst := float64(limit.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+		g.bucket = &tokenBucket{
+			rate:   limit.RequestsPerSecond,
+			burst:  burst,
+			tokens: burst,
+			last:   time.Now(),
+		}
+	}
+	if limit.MaxInFlight > 0 {
+		g.sem = make(chan struct{}, limit.MaxInFlight)
+	}
+	return g
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	return &rateLimiter{
+		groups: map[EndpointGroup]*groupLimiter{
+			RelayEndpoints:     newGroupLimiter(RelayEndpoints, limits.Relay),
+			DataEndpoints:      newGroupLimiter(DataEndpoints, limits.Data),
+			DashboardEndpoints: newGroupLimiter(DashboardEndpoints, limits.Dashboard),
+		},
+		onWait: limits.OnWait,
+	}
+}
+
+// acquire blocks until the group allows one more request and returns the
+// function releasing its in-flight slot.
+func (l *rateLimiter) acquire(ctx context.Context, group EndpointGroup) (func(), error) {
+	g := l.groups[group]
+	start := time.Now()
+	if g.bucket != nil {
+		if wait := g.bucket.reserve(start); wait > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				g.bucket.cancel()
+				return nil, err
+			}
+		}
+	}
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-ctx.Done():
+			if g.bucket != nil {
+				g.bucket.cancel()
+			}
+			return nil, ctx.Err()
+		}
+	}
+	wait := time.Since(start)
+
+	g.mu.Lock()
+	g.stats.Requests++
+	g.stats.InFlight++
+	if wait > time.Millisecond {
+		g.stats.Waited++
+		g.stats.TotalWait += wait
+		if wait > g.stats.MaxWait {
+			g.stats.MaxWait = wait
+		}
+	}
+	g.mu.Unlock()
+	if l.onWait != nil {
+		l.onWait(group, wait)
+	}
+
+	return func() {
+		g.mu.Lock()
+		g.stats.InFlight--
+		g.mu.Unlock()
+		if g.sem != nil {
+			<-g.sem
+		}
+	}, nil
+}
+
+func (l *rateLimiter) stats() map[EndpointGroup]LimiterStats {
+	stats := make(map[EndpointGroup]LimiterStats, len(l.groups))
+	for group, g := range l.groups {
+		g.mu.Lock()
+		stats[group] = g.stats
+		g.mu.Unlock()
+	}
+	return stats
+}
@@ -0,0 +1,176 @@
+package metax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Relayer names reported by Submission.Relayer.
+const (
+	RelayerBiconomy   = "biconomy"
+	RelayerBiconomyV1 = "biconomy-v1"
+	RelayerSelf       = "self"
+)
+
+// Relayer gets a signed meta transaction request on chain. The returned
+// response carries the transaction hash; a response returned with an error
+// means the relayer answered and rejected the request.
+type Relayer interface {
+	Name() string
+	Relay(ctx context.Context, req *MetaTxRequest, metaTxMessage *MetaTxMessage) (*MetaTxResponse, error)
+}
+
+type biconomyRelayer struct {
+	b *Bcnmy
+}
+
+// BiconomyRelayer relays through the v2 meta-tx/native API, SendMetaNativeTx.
+func (b *Bcnmy) BiconomyRelayer() Relayer {
+	return &biconomyRelayer{b: b}
+}
+
+func (r *biconomyRelayer) Name() string {
+	return RelayerBiconomy
+}
+
+func (r *biconomyRelayer) Relay(ctx context.Context, req *MetaTxRequest, metaTxMessage *MetaTxMessage) (*MetaTxResponse, error) {
+	return r.b.SendMetaNativeTxContext(ctx, req)
+}
+
+type biconomyV1Relayer struct {
+	b *Bcnmy
+}
+
+// BiconomyV1Relayer relays through the v1 gasless-meta API, SendMetaNativeTxV1,
+// polling the transaction status for the hash.
+func (b *Bcnmy) BiconomyV1Relayer() Relayer {
+	return &biconomyV1Relayer{b: b}
+}
+
+func (r *biconomyV1Relayer) Name() string {
+	return RelayerBiconomyV1
+}
+
+func (r *biconomyV1Relayer) Relay(ctx context.Context, req *MetaTxRequest, metaTxMessage *MetaTxMessage) (*MetaTxResponse, error) {
+	return r.b.SendMetaNativeTxV1Context(ctx, req)
+}
+
+// WithRelayers makes RawTransact, EnhanceTransact and their variants try
+// relayers in order, moving to the next one while policy accepts the failure
+// (DefaultFallbackPolicy when nil). Without it requests go to BiconomyRelayer,
+// then to the WithSelfRelay account if one is configured.
+func (b *Bcnmy) WithRelayers(policy FallbackPolicy, relayers ...Relayer) *Bcnmy {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.relayers = relayers
+	b.relayPolicy = policy
+	return b
+}
+
+// relayChain is the relayers of a call and the failover policy between them.
+// Requests paying their fee in a token only go to self relayers, which send
+// them through the fee proxy: Biconomy's native API relays them through the
+// forwarder, which does not charge the fee.
+func (b *Bcnmy) relayChain(opts *TransactOpts, metaTxMessage *MetaTxMessage) ([]Relayer, FallbackPolicy, error) {
+	b.mu.RLock()
+	relayers, policy := b.relayers, b.relayPolicy
+	b.mu.RUnlock()
+	if opts != nil && len(opts.Relayers) > 0 {
+		relayers = opts.Relayers
+	}
+	if len(relayers) == 0 {
+		relayers = []Relayer{b.BiconomyRelayer()}
+		if b.selfRelayer != nil {
+			relayers = append(relayers, b.selfRelayer)
+			policy = b.selfRelayer.config.Policy
+		}
+	}
+	if policy == nil {
+		policy = DefaultFallbackPolicy
+	}
+	if metaTxMessage.Token != (common.Address{}) {
+		var feeRelayers []Relayer
+		for _, relayer := range relayers {
+			if _, ok := relayer.(*selfRelayer); ok {
+				feeRelayers = append(feeRelayers, relayer)
+			}
+		}
+		if len(feeRelayers) == 0 && b.selfRelayer != nil {
+			feeRelayers = []Relayer{b.selfRelayer}
+		}
+		if len(feeRelayers) == 0 {
+			return nil, nil, fmt.Errorf("%w, see WithSelfRelay", ErrFeeRelayUnavailable)
+		}
+		relayers = feeRelayers
+	}
+	return relayers, policy, nil
+}
+
+// relayFailover tries the relayers of the call until one takes req, recording
+// the one answering last in sub.Relayer.
+func (b *Bcnmy) relayFailover(ctx context.Context, opts *TransactOpts, sub *Submission, req *MetaTxRequest, metaTxMessage *MetaTxMessage) (*MetaTxResponse, error) {
+	relayers, policy, err := b.relayChain(opts, metaTxMessage)
+	if err != nil {
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	var attempts []RelayAttempt
+	for i, relayer := range relayers {
+		sub.Relayer = relayer.Name()
+		resp, err := relayer.Relay(ctx, req, metaTxMessage)
+		if err == nil {
+			if i > 0 {
+				b.logger.Infof("Relayed by %s after %v failed relayers", relayer.Name(), i)
+			}
+			return resp, nil
+		}
+		attempts = append(attempts, RelayAttempt{Relayer: relayer.Name(), Response: resp, Err: err})
+		if i == len(relayers)-1 || !policy(resp, err) {
+			break
+		}
+		b.logger.WithError(err).Warnf("Relayer %s failed, failing over to %s", relayer.Name(), relayers[i+1].Name())
+	}
+	last := attempts[len(attempts)-1]
+	if len(attempts) == 1 {
+		return last.Response, last.Err
+	}
+	return last.Response, &FailoverError{Attempts: attempts}
+}
+
+// RelayAttempt is the failure of one relayer.
+type RelayAttempt struct {
+	Relayer  string
+	Response *MetaTxResponse
+	Err      error
+}
+
+// FailoverError reports a request every relayer tried failed on. It matches
+// the errors of all attempts with errors.Is and unwraps to the last one.
+type FailoverError struct {
+	Attempts []RelayAttempt
+}
+
+func (e *FailoverError) Error() string {
+	msgs := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", attempt.Relayer, attempt.Err))
+	}
+	return fmt.Sprintf("All relayers failed: %s", strings.Join(msgs, "; "))
+}
+
+func (e *FailoverError) Is(target error) bool {
+	for _, attempt := range e.Attempts {
+		if errors.Is(attempt.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *FailoverError) Unwrap() error {
+	return e.Attempts[len(e.Attempts)-1].Err
+}
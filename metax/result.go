@@ -0,0 +1,267 @@
+package metax
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
+)
+
+var (
+	// selector of Error(string)
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// selector of Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// MetaTxResult is the decoded outcome of a mined meta transaction.
+type MetaTxResult struct {
+	Receipt *types.Receipt
+	// Success is set when the transaction and the forwarded call both succeeded
+	Success bool
+	// Reverted is set when the transaction itself reverted
+	Reverted bool
+	// ReturnData is what the DAPP returned to the forwarder
+	ReturnData []byte
+	// Err describes the failure, nil on success
+	Err *CallError
+	// Events are the receipt logs, decoded when a registered ABI knows them
+	Events []DecodedEvent
+}
+
+// CallError is a decoded revert: Error(string), Panic(uint256) or a custom
+// error of a registered ABI. Name is empty when the data matches none.
+type CallError struct {
+	Data   []byte
+	Name   string
+	Args   map[string]interface{}
+	Reason string
+	// PanicCode is set for Panic(uint256), e.g. 0x11 for an overflow
+	PanicCode *big.Int
+}
+
+func (e *CallError) Error() string {
+	switch {
+	case e.Name == "Error":
+		return fmt.Sprintf("Reverted: %s", e.Reason)
+	case e.Name == "Panic":
+		return fmt.Sprintf("Panic: 0x%x", e.PanicCode)
+	case e.Name != "":
+		return fmt.Sprintf("Reverted with %s%v", e.Name, e.Args)
+	case len(e.Data) == 0:
+		return "Reverted without reason"
+	}
+	return fmt.Sprintf("Reverted with data %s", hexutil.Encode(e.Data))
+}
+
+// DecodedEvent is a receipt log. Name is empty when no registered ABI of
+// the emitting contract knows the event.
+type DecodedEvent struct {
+	Address common.Address
+	Name    string
+	Sig     string
+	Args    map[string]interface{}
+	Log     *types.Log
+}
+
+// Result waits like Wait and decodes the outcome.
+func (s *Submission) Result(ctx context.Context, confirmations uint64) (*MetaTxResult, error) {
+	tx, receipt, err := s.Wait(ctx, confirmations)
+	if err != nil {
+		return nil, err
+	}
+	return s.b.DecodeResult(ctx, tx, receipt)
+}
+
+// DecodeResult decodes the outcome of a mined forwarder transaction. The
+// forwarder's (success, ret) is not logged, so the transaction is replayed
+// with eth_call on the parent block; transactions before it in the same
+// block are not taken into account.
+func (b *Bcnmy) DecodeResult(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (*MetaTxResult, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	result := &MetaTxResult{
+		Receipt:  receipt,
+		Reverted: receipt.Status == types.ReceiptStatusFailed,
+		Events:   b.DecodeLogs(receipt.Logs),
+	}
+	if tx == nil {
+		result.Success = !result.Reverted
+		return result, nil
+	}
+	dapp := b.forwardedDapp(tx.Data())
+
+	call := ethereum.CallMsg{
+		From:  b.transactionSender(tx),
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	var parent *big.Int
+	if receipt.BlockNumber != nil && receipt.BlockNumber.Sign() > 0 {
+		parent = new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	}
+	out, err := b.ethClient.CallContract(ctx, call, parent)
+	if err != nil {
+		revertData, ok := revertDataOf(err)
+		if !ok {
+			b.logger.WithError(err).Error("Replay forwarder transaction failed")
+			return nil, err
+		}
+		if !result.Reverted {
+			b.logger.Warnf("Replay of %s reverted but the transaction did not", tx.Hash().Hex())
+		}
+		result.Err = b.DecodeError(dapp, revertData)
+		return result, nil
+	}
+
+	forwarderABI, err := forwarder.ForwarderMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	// executeEIP712 and executePersonalSign both return (bool success, bytes ret)
+	ret, err := forwarderABI.Unpack("executeEIP712", out)
+	if err != nil {
+		return nil, fmt.Errorf("Unpack forwarder execution result failed: %v", err)
+	}
+	success := ret[0].(bool)
+	result.ReturnData = ret[1].([]byte)
+	result.Success = success && !result.Reverted
+	if !success {
+		result.Err = b.DecodeError(dapp, result.ReturnData)
+	}
+	return result, nil
+}
+
+// transactionSender recovers the sender of tx, DefaultSimulationRelayer when
+// it cannot.
+func (b *Bcnmy) transactionSender(tx *types.Transaction) common.Address {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return DefaultSimulationRelayer
+	}
+	return from
+}
+
+// forwardedDapp is the registered DAPP a forwarder call is for, nil when
+// unknown.
+func (b *Bcnmy) forwardedDapp(data []byte) *DappHandle {
+	forwarderABI, err := forwarder.ForwarderMetaData.GetAbi()
+	if err != nil || len(data) < 4 {
+		return nil
+	}
+	method, err := forwarderABI.MethodById(data[:4])
+	if err != nil {
+		return nil
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(args) == 0 {
+		return nil
+	}
+	req, ok := abi.ConvertType(args[0], new(forwarder.ERC20ForwardRequestTypesERC20ForwardRequest)).(*forwarder.ERC20ForwardRequestTypesERC20ForwardRequest)
+	if !ok {
+		return nil
+	}
+	dapp, _ := b.Dapp(req.To)
+	return dapp
+}
+
+// DecodeError decodes revert data with Error(string), Panic(uint256) and the
+// custom errors of dapp, then of every registered DAPP. dapp may be nil.
+func (b *Bcnmy) DecodeError(dapp *DappHandle, data []byte) *CallError {
+	callErr := &CallError{Data: data}
+	if len(data) < 4 {
+		return callErr
+	}
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			callErr.Name, callErr.Reason = "Error", reason
+		}
+		return callErr
+	case bytes.Equal(data[:4], panicSelector):
+		if len(data) == 4+32 {
+			callErr.Name, callErr.PanicCode = "Panic", new(big.Int).SetBytes(data[4:])
+		}
+		return callErr
+	}
+
+	dapps := b.Dapps()
+	if dapp != nil {
+		dapps = append([]*DappHandle{dapp}, dapps...)
+	}
+	for _, d := range dapps {
+		for _, abiErr := range d.abi.Errors {
+			if !bytes.Equal(data[:4], abiErr.ID[:4]) {
+				continue
+			}
+			args := make(map[string]interface{})
+			if err := abiErr.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+				continue
+			}
+			callErr.Name, callErr.Args = abiErr.Name, args
+			return callErr
+		}
+	}
+	return callErr
+}
+
+// DecodeLogs decodes logs with the ABI of the registered DAPP, or the
+// forwarder, that emitted them.
+func (b *Bcnmy) DecodeLogs(logs []*types.Log) []DecodedEvent {
+	forwarderABI, _ := forwarder.ForwarderMetaData.GetAbi()
+	b.mu.RLock()
+	forwarderAddress := b.trustedForwarder.Address
+	b.mu.RUnlock()
+
+	events := make([]DecodedEvent, 0, len(logs))
+	for _, log := range logs {
+		event := DecodedEvent{Address: log.Address, Log: log}
+		var contractABI *abi.ABI
+		if dapp, ok := b.Dapp(log.Address); ok {
+			contractABI = &dapp.abi
+		} else if log.Address == forwarderAddress {
+			contractABI = forwarderABI
+		}
+		if contractABI != nil && len(log.Topics) > 0 {
+			if abiEvent, err := contractABI.EventByID(log.Topics[0]); err == nil {
+				if args, err := unpackLog(abiEvent, log); err == nil {
+					event.Name, event.Sig, event.Args = abiEvent.Name, abiEvent.Sig, args
+				} else {
+					b.logger.WithError(err).Warnf("Unpack %s log failed", abiEvent.Name)
+				}
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func unpackLog(event *abi.Event, log *types.Log) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	if len(log.Data) > 0 {
+		if err := event.Inputs.NonIndexed().UnpackIntoMap(args, log.Data); err != nil {
+			return nil, err
+		}
+	}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
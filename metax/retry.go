@@ -0,0 +1,154 @@
+package metax
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed HTTP attempt is tried again and how
+// long to wait before doing so. attempt starts at 1 for the first try.
+type RetryPolicy interface {
+	NextBackoff(attempt int, res *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff waits InitialBackoff*Multiplier^(attempt-1), capped at
+// MaxBackoff, randomised by +/- Jitter, and honours a Retry-After header.
+type ExponentialBackoff struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64 // fraction of the backoff, 0 to 1
+	// Retryable classifies an attempt, DefaultRetryable when nil
+	Retryable func(res *http.Response, err error) bool
+}
+
+var DefaultRetryPolicy RetryPolicy = &ExponentialBackoff{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// NoRetry makes exactly one attempt.
+var NoRetry RetryPolicy = &ExponentialBackoff{MaxAttempts: 1}
+
+func (p *ExponentialBackoff) NextBackoff(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	if !retryable(res, err) {
+		return 0, false
+	}
+	if wait, ok := retryAfter(res); ok {
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+		return wait, true
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff), true
+}
+
+// DefaultRetryable retries transport errors other than a cancelled or expired
+// context, 429 Too Many Requests and the 502, 503 and 504 gateway answers.
+func DefaultRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// safeToResend reports whether a failed attempt provably never reached the
+// server, so that a non-idempotent request such as a relay can be resent.
+func safeToResend(res *http.Response, err error) bool {
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	return res.StatusCode == http.StatusTooManyRequests
+}
+
+// relayNotSent reports whether a failed relay provably never handed the
+// request to a relayer, or was answered and rejected, so its nonce can be
+// used again. Timeouts and dropped connections are not: the relayer may have
+// broadcast the request.
+func relayNotSent(err error) bool {
+	var failover *FailoverError
+	if errors.As(err, &failover) {
+		for _, attempt := range failover.Attempts {
+			if attempt.Response == nil && !relayNotSent(attempt.Err) {
+				return false
+			}
+		}
+		return true
+	}
+	if errors.Is(err, ErrLimitExhausted) || errors.Is(err, ErrRelayerRejected) || errors.Is(err, ErrFeeRelayUnavailable) ||
+		errors.Is(err, ErrGasPriceAboveMax) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode < http.StatusInternalServerError
+	}
+	return err != nil && safeToResend(nil, err)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,285 @@
+package metax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/oblzh/bcnmy-go/abi/erc20forwarder"
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
+)
+
+// GasPricing selects the transaction type of the self relayer.
+type GasPricing int
+
+const (
+	// GasPricingAuto sends EIP-1559 transactions when the head block has a
+	// base fee and legacy ones otherwise
+	GasPricingAuto GasPricing = iota
+	GasPricingLegacy
+	GasPricingEIP1559
+)
+
+// FallbackPolicy decides whether a request Biconomy failed to relay is sent
+// by the self relayer instead. resp is nil when Biconomy was not reached.
+type FallbackPolicy func(resp *MetaTxResponse, err error) bool
+
+// DefaultFallbackPolicy falls back when Biconomy could not be dialed, answers
+// 429 or 5xx (as HTTP status or response code), or a DApp, User or API limit
+// is exhausted. Rejected requests, e.g. for a bad signature or apiId, are not
+// retried by the self relayer, nor are timeouts and dropped connections, since
+// Biconomy may have broadcast the request already.
+func DefaultFallbackPolicy(resp *MetaTxResponse, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrLimitExhausted) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return unavailableStatus(statusErr.StatusCode)
+	}
+	if resp == nil {
+		return err != nil && safeToResend(nil, err)
+	}
+	return unavailableStatus(resp.Code)
+}
+
+func unavailableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// SelfRelayConfig configures relaying forward requests from a funded account
+// of our own, by calling the forwarder's executeEIP712 or executePersonalSign,
+// or the fee proxy's for requests paying their fee in an ERC20 token.
+type SelfRelayConfig struct {
+	// Signer is the relayer account paying the gas
+	Signer TxSigner
+	// Policy picks the Biconomy failures that fall back, DefaultFallbackPolicy when nil
+	Policy     FallbackPolicy
+	GasPricing GasPricing
+	// MaxGasPrice caps the legacy gas price or the EIP-1559 fee cap, no cap
+	// when nil. Requests fail with ErrGasPriceAboveMax while the suggested
+	// gas price or the base fee is above it.
+	MaxGasPrice *big.Int
+}
+
+type selfRelayer struct {
+	b      *Bcnmy
+	config SelfRelayConfig
+
+	// mu serialises sends so the relayer nonces go out in order
+	mu    sync.Mutex
+	nonce *uint64
+}
+
+func (b *Bcnmy) SelfRelay(metaTxMessage *MetaTxMessage, signature []byte) (*types.Transaction, error) {
+	return b.SelfRelayContext(b.ctx, metaTxMessage, signature)
+}
+
+func (b *Bcnmy) SelfRelayContext(ctx context.Context, metaTxMessage *MetaTxMessage, signature []byte) (*types.Transaction, error) {
+	return b.SelfRelayWithOpts(ctx, nil, metaTxMessage, signature)
+}
+
+// SelfRelayWithOpts sends a signed forward request to the forwarder from the
+// account configured with WithSelfRelay, bypassing Biconomy.
+func (b *Bcnmy) SelfRelayWithOpts(ctx context.Context, opts *TransactOpts, metaTxMessage *MetaTxMessage, signature []byte) (*types.Transaction, error) {
+	if b.selfRelayer == nil {
+		err := fmt.Errorf("Self relay not configured, see WithSelfRelay")
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+	return b.selfRelayer.execute(ctx, opts.signatureType(), metaTxMessage, signature)
+}
+
+// SelfRelayer returns a Relayer sending from config.Signer, for WithRelayers.
+// config.Policy is not used by it.
+func (b *Bcnmy) SelfRelayer(config SelfRelayConfig) (Relayer, error) {
+	if config.Signer == nil {
+		return nil, fmt.Errorf("SelfRelayer needs a Signer")
+	}
+	return &selfRelayer{b: b, config: config}, nil
+}
+
+func (r *selfRelayer) Name() string {
+	return RelayerSelf
+}
+
+func (r *selfRelayer) Relay(ctx context.Context, req *MetaTxRequest, metaTxMessage *MetaTxMessage) (*MetaTxResponse, error) {
+	signature, err := requestSignature(req)
+	if err != nil {
+		return nil, err
+	}
+	signatureType := req.SignatureType
+	if signatureType == "" {
+		signatureType = SignatureEIP712Type
+	}
+	tx, err := r.execute(ctx, signatureType, metaTxMessage, signature)
+	if err != nil {
+		return nil, err
+	}
+	return &MetaTxResponse{TxHash: tx.Hash(), Message: "Self relayed"}, nil
+}
+
+func (r *selfRelayer) execute(ctx context.Context, signatureType string, metaTxMessage *MetaTxMessage, signature []byte) (*types.Transaction, error) {
+	b := r.b
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	req, err := metaTxMessage.ForwardRequest()
+	if err != nil {
+		return nil, err
+	}
+	executeEIP712 := b.trustedForwarder.Contract.ForwarderTransactor.ExecuteEIP712
+	executePersonalSign := b.trustedForwarder.Contract.ForwarderTransactor.ExecutePersonalSign
+	if metaTxMessage.Token != (common.Address{}) {
+		// the fee proxy forwards the request and charges the token fee
+		if b.feeProxy == (common.Address{}) {
+			return nil, fmt.Errorf("ERC20 fee proxy not configured, see WithERC20FeeProxy")
+		}
+		proxy, err := erc20forwarder.NewERC20FeeProxyTransactor(b.feeProxy, b.ethClient)
+		if err != nil {
+			return nil, err
+		}
+		executeEIP712 = func(txOpts *bind.TransactOpts, req forwarder.ERC20ForwardRequestTypesERC20ForwardRequest, domainSeparator [32]byte, sig []byte) (*types.Transaction, error) {
+			return proxy.ExecuteEIP712(txOpts, erc20forwarder.ERC20ForwardRequestTypesERC20ForwardRequest(req), domainSeparator, sig)
+		}
+		executePersonalSign = func(txOpts *bind.TransactOpts, req forwarder.ERC20ForwardRequestTypesERC20ForwardRequest, sig []byte) (*types.Transaction, error) {
+			return proxy.ExecutePersonalSign(txOpts, erc20forwarder.ERC20ForwardRequestTypesERC20ForwardRequest(req), sig)
+		}
+	}
+
+	var send func(txOpts *bind.TransactOpts) (*types.Transaction, error)
+	switch signatureType {
+	case SignatureEIP712Type:
+		typedData := b.forwardTypedData(metaTxMessage)
+		domainSeparator, err := typedData.HashStruct(EIP712DomainType, typedData.Domain.Map())
+		if err != nil {
+			return nil, err
+		}
+		send = func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+			return executeEIP712(txOpts, req, common.BytesToHash(domainSeparator), signature)
+		}
+	case SignaturePersonalType:
+		send = func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+			return executePersonalSign(txOpts, req, signature)
+		}
+	default:
+		return nil, fmt.Errorf("Signature type not supported: %s", signatureType)
+	}
+
+	tx, err := r.send(ctx, send)
+	if err != nil {
+		b.logger.WithError(err).Error("Self relay failed")
+		return nil, err
+	}
+	b.logger.Infof("Self relayed %s from %s", tx.Hash().Hex(), r.config.Signer.GetAddress().Hex())
+	return tx, nil
+}
+
+// send prices and signs a forwarder transaction with the next relayer nonce.
+// A failed send drops the local nonce so the next one resyncs with the node.
+func (r *selfRelayer) send(ctx context.Context, send func(txOpts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	b := r.b
+	from := r.config.Signer.GetAddress()
+	txOpts := &bind.TransactOpts{
+		From:    from,
+		Context: ctx,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return r.config.Signer.SignTx(tx, b.chainId)
+		},
+	}
+	if err := r.price(ctx, txOpts); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nonce == nil {
+		nonce, err := b.ethClient.PendingNonceAt(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("PendingNonceAt of relayer %s failed: %w", from.Hex(), err)
+		}
+		r.nonce = &nonce
+	}
+	txOpts.Nonce = new(big.Int).SetUint64(*r.nonce)
+	tx, err := send(txOpts)
+	if err != nil {
+		r.nonce = nil
+		return nil, err
+	}
+	*r.nonce++
+	return tx, nil
+}
+
+// price sets the legacy gas price or the EIP-1559 fee cap and tip.
+func (r *selfRelayer) price(ctx context.Context, txOpts *bind.TransactOpts) error {
+	b := r.b
+	var baseFee *big.Int
+	if r.config.GasPricing != GasPricingLegacy {
+		if heads, ok := b.ethClient.(headerReader); ok {
+			head, err := heads.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("HeaderByNumber failed: %w", err)
+			}
+			baseFee = head.BaseFee
+		}
+		if baseFee == nil && r.config.GasPricing == GasPricingEIP1559 {
+			return fmt.Errorf("EIP-1559 gas pricing needs a base fee, the chain head has none")
+		}
+	}
+
+	maxGasPrice := r.config.MaxGasPrice
+	if baseFee == nil {
+		gasPrice, err := b.ethClient.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("SuggestGasPrice failed: %w", err)
+		}
+		if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+			return fmt.Errorf("%w: suggested %v, max %v", ErrGasPriceAboveMax, gasPrice, maxGasPrice)
+		}
+		txOpts.GasPrice = gasPrice
+		return nil
+	}
+	// a fee cap below the base fee is never included
+	if maxGasPrice != nil && baseFee.Cmp(maxGasPrice) > 0 {
+		return fmt.Errorf("%w: base fee %v, max %v", ErrGasPriceAboveMax, baseFee, maxGasPrice)
+	}
+	tip, err := b.ethClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("SuggestGasTipCap failed: %w", err)
+	}
+	feeCap := new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+	if maxGasPrice != nil && feeCap.Cmp(maxGasPrice) > 0 {
+		feeCap = maxGasPrice
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = feeCap
+	}
+	txOpts.GasFeeCap, txOpts.GasTipCap = feeCap, tip
+	return nil
+}
+
+// requestSignature is the signature carried by a MetaTxRequest, its last param.
+func requestSignature(req *MetaTxRequest) ([]byte, error) {
+	if len(req.Params) == 0 {
+		return nil, fmt.Errorf("MetaTxRequest has no params")
+	}
+	encoded, ok := req.Params[len(req.Params)-1].(string)
+	if !ok {
+		return nil, fmt.Errorf("MetaTxRequest signature param is %T", req.Params[len(req.Params)-1])
+	}
+	return hexutil.Decode(encoded)
+}
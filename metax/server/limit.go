@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket of PerSecond requests refilling up to Burst. A
+// negative PerSecond disables it.
+type Limit struct {
+	PerSecond float64
+	Burst     int
+}
+
+var (
+	// DefaultClientLimit applies to the POST requests of each client
+	DefaultClientLimit = Limit{PerSecond: 5, Burst: 20}
+	// DefaultFromLimit applies to the typed data and submissions of each signer
+	DefaultFromLimit = Limit{PerSecond: 1, Burst: 10}
+)
+
+// DefaultMaxTracked caps the clients and signers each limiter remembers and
+// the submissions kept for status queries.
+const DefaultMaxTracked = 10000
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// keyLimiter is a token bucket per key, remembering at most max keys: idle
+// ones are forgotten first, new keys are refused while none is idle.
+type keyLimiter struct {
+	limit Limit
+	max   int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func newKeyLimiter(limit Limit, max int) *keyLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &keyLimiter{limit: limit, max: max, buckets: make(map[string]*bucket)}
+}
+
+func (l *keyLimiter) allow(key string) bool {
+	if l.limit.PerSecond < 0 {
+		return true
+	}
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bk, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.max {
+			l.forgetIdleLocked(now)
+		}
+		if len(l.buckets) >= l.max {
+			return false
+		}
+		bk = &bucket{tokens: float64(l.limit.Burst), at: now}
+		l.buckets[key] = bk
+	}
+	l.refill(bk, now)
+	if bk.tokens < 1 {
+		return false
+	}
+	bk.tokens--
+	return true
+}
+
+func (l *keyLimiter) refill(bk *bucket, now time.Time) {
+	bk.tokens += now.Sub(bk.at).Seconds() * l.limit.PerSecond
+	if bk.tokens > float64(l.limit.Burst) {
+		bk.tokens = float64(l.limit.Burst)
+	}
+	bk.at = now
+}
+
+// forgetIdleLocked drops the buckets refilled to their burst, they behave
+// like new ones.
+func (l *keyLimiter) forgetIdleLocked(now time.Time) {
+	for key, bk := range l.buckets {
+		l.refill(bk, now)
+		if bk.tokens >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RemoteIP is the default Config.ClientKey: the IP the request came from.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
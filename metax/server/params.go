@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var bigIntType = reflect.TypeOf(&big.Int{})
+
+// decodeParams converts JSON params to the Go values go-ethereum packs for
+// inputs. Integers are JSON numbers or decimal / 0x strings, addresses and
+// bytes 0x strings, tuples objects keyed by component name or arrays.
+func decodeParams(inputs abi.Arguments, raw []json.RawMessage) ([]interface{}, error) {
+	if len(raw) != len(inputs) {
+		return nil, fmt.Errorf("got %v params, want %v", len(raw), len(inputs))
+	}
+	params := make([]interface{}, len(raw))
+	for i, input := range inputs {
+		value, err := decodeValue(input.Type, raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("param %v %s: %v", i, input.Name, err)
+		}
+		params[i] = value.Interface()
+	}
+	return params, nil
+}
+
+func decodeValue(t abi.Type, raw json.RawMessage) (reflect.Value, error) {
+	switch t.T {
+	case abi.AddressTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("%q is not an address", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.BoolTy:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case abi.StringTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(s), nil
+	case abi.IntTy, abi.UintTy:
+		return decodeInteger(t, raw)
+	case abi.BytesTy:
+		b, err := decodeBytes(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy:
+		b, err := decodeBytes(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("got %v bytes, want %v", len(b), t.Size)
+		}
+		value := reflect.New(t.GetType()).Elem()
+		reflect.Copy(value, reflect.ValueOf(b))
+		return value, nil
+	case abi.SliceTy, abi.ArrayTy:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return reflect.Value{}, err
+		}
+		var value reflect.Value
+		if t.T == abi.SliceTy {
+			value = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("got %v items, want %v", len(items), t.Size)
+			}
+			value = reflect.New(t.GetType()).Elem()
+		}
+		for i, item := range items {
+			elem, err := decodeValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("item %v: %v", i, err)
+			}
+			value.Index(i).Set(elem)
+		}
+		return value, nil
+	case abi.TupleTy:
+		items, err := tupleItems(t, raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value := reflect.New(t.GetType()).Elem()
+		for i, elemType := range t.TupleElems {
+			elem, err := decodeValue(*elemType, items[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s: %v", t.TupleRawNames[i], err)
+			}
+			value.Field(i).Set(elem)
+		}
+		return value, nil
+	}
+	return reflect.Value{}, fmt.Errorf("type %s not supported", t.String())
+}
+
+func decodeInteger(t abi.Type, raw json.RawMessage) (reflect.Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return reflect.Value{}, err
+	}
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return reflect.Value{}, fmt.Errorf("%s is not an integer", raw)
+	}
+	// base 10 unless 0x prefixed: SetString's base 0 would read "010" as
+	// octal and accept 0b, 0o and _ separators
+	var n *big.Int
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		var err error
+		if n, err = hexutil.DecodeBig(s); err != nil {
+			return reflect.Value{}, fmt.Errorf("%q: %v", s, err)
+		}
+	} else {
+		var ok bool
+		if n, ok = new(big.Int).SetString(s, 10); !ok {
+			return reflect.Value{}, fmt.Errorf("%q is not a decimal integer", s)
+		}
+	}
+
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+	if t.T == abi.IntTy {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	max.Sub(max, big.NewInt(1))
+	if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+		return reflect.Value{}, fmt.Errorf("%v out of %s range", n, t.String())
+	}
+
+	goType := t.GetType()
+	if goType == bigIntType {
+		return reflect.ValueOf(n), nil
+	}
+	value := reflect.New(goType).Elem()
+	if t.T == abi.UintTy {
+		value.SetUint(n.Uint64())
+	} else {
+		value.SetInt(n.Int64())
+	}
+	return value, nil
+}
+
+func decodeBytes(raw json.RawMessage) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return hexutil.Decode(s)
+}
+
+// tupleItems orders the components of a tuple given as object or array.
+func tupleItems(t abi.Type, raw json.RawMessage) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		if len(items) != len(t.TupleElems) {
+			return nil, fmt.Errorf("got %v components, want %v", len(items), len(t.TupleElems))
+		}
+		return items, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range t.TupleRawNames {
+		item, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("component %s missing", name)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
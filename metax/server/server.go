@@ -0,0 +1,627 @@
+// Package server is an HTTP gateway relaying frontend signed meta
+// transactions through a metax client: the frontend fetches the typed data
+// of a method call, signs it with eth_signTypedData_v4, submits the
+// signature and polls the status.
+//
+//	POST /v1/typed-data    TypedDataRequest -> metax.ForwardRequestPayload
+//	POST /v1/submit        SubmitRequest -> StatusResponse
+//	GET  /v1/status/{id}   StatusResponse
+//	GET  /v1/limits?dapp=&from=&method=   metax.CheckLimitResponse
+//	GET  /v1/schemas/{name}.json          JSON schema of the bodies
+//
+// The limits method is a name, canonical signature or selector; an
+// overloaded name needs the signature or selector, there are no params to
+// pick the overload from.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/sirupsen/logrus"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+const (
+	// DefaultMaxBodyBytes limits request bodies
+	DefaultMaxBodyBytes = 64 << 10
+	// DefaultStatusTTL is how long submissions can be queried
+	DefaultStatusTTL = 24 * time.Hour
+	// DefaultWaitTimeout bounds the background wait of a submission
+	DefaultWaitTimeout = 30 * time.Minute
+)
+
+var ErrNotAllowed = errors.New("Not allowed by the gateway policy")
+
+// Policy lists the DAPPs the gateway serves and, for each, the methods by
+// name, canonical signature or selector; an empty list allows every method.
+// A nil Policy serves every DAPP registered on the client.
+type Policy map[common.Address][]string
+
+type Config struct {
+	// AllowedOrigins are the CORS origins, "*" allows any; none sends no CORS headers
+	AllowedOrigins []string
+	Policy         Policy
+	// Confirmations a submission is waited for, 1 when zero
+	Confirmations uint64
+	MaxBodyBytes  int64
+	StatusTTL     time.Duration
+	WaitTimeout   time.Duration
+	// ClientLimit rate limits the POST requests of each client,
+	// DefaultClientLimit when zero
+	ClientLimit Limit
+	// FromLimit rate limits the typed data and submissions of each signer,
+	// DefaultFromLimit when zero
+	FromLimit Limit
+	// ClientKey identifies the client of a request, RemoteIP when nil. Behind
+	// a proxy it should read the forwarded client address instead.
+	ClientKey func(r *http.Request) string
+	// MaxTracked caps the clients and signers rate limited and the
+	// submissions kept, DefaultMaxTracked when zero
+	MaxTracked int
+	Logger     *logrus.Entry
+}
+
+type TypedDataRequest struct {
+	Dapp   common.Address    `json:"dapp"`
+	From   common.Address    `json:"from"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type SubmitRequest struct {
+	Payload   *metax.ForwardRequestPayload `json:"payload"`
+	Signature hexutil.Bytes                `json:"signature"`
+}
+
+type StatusResponse struct {
+	ID          string         `json:"id"`
+	TxHash      common.Hash    `json:"txHash"`
+	Status      metax.TxStatus `json:"status"`
+	Relayer     string         `json:"relayer,omitempty"`
+	BlockNumber uint64         `json:"blockNumber,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	SubmittedAt time.Time      `json:"submittedAt"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type tracked struct {
+	sub *metax.Submission
+
+	mu          sync.Mutex
+	blockNumber uint64
+	err         error
+}
+
+// Server is the gateway http.Handler. Close stops the background waits.
+type Server struct {
+	b      *metax.Bcnmy
+	config Config
+	logger *logrus.Entry
+	mux    *http.ServeMux
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	clients *keyLimiter
+	senders *keyLimiter
+
+	mu          sync.Mutex
+	submissions map[string]*tracked
+	// submitting counts the submissions in flight, holding a MaxTracked slot
+	submitting int
+}
+
+func New(b *metax.Bcnmy, config Config) (*Server, error) {
+	if b == nil {
+		return nil, fmt.Errorf("Server needs a metax client")
+	}
+	if config.Confirmations == 0 {
+		config.Confirmations = 1
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if config.StatusTTL <= 0 {
+		config.StatusTTL = DefaultStatusTTL
+	}
+	if config.WaitTimeout <= 0 {
+		config.WaitTimeout = DefaultWaitTimeout
+	}
+	if config.ClientLimit == (Limit{}) {
+		config.ClientLimit = DefaultClientLimit
+	}
+	if config.FromLimit == (Limit{}) {
+		config.FromLimit = DefaultFromLimit
+	}
+	if config.ClientKey == nil {
+		config.ClientKey = RemoteIP
+	}
+	if config.MaxTracked <= 0 {
+		config.MaxTracked = DefaultMaxTracked
+	}
+	if config.Logger == nil {
+		config.Logger = logrus.WithField("metax", "server")
+	}
+	for dapp := range config.Policy {
+		if _, ok := b.Dapp(dapp); !ok {
+			return nil, fmt.Errorf("Policy DAPP %s is not registered", dapp.Hex())
+		}
+	}
+	s := &Server{
+		b:           b,
+		config:      config,
+		logger:      config.Logger,
+		mux:         http.NewServeMux(),
+		clients:     newKeyLimiter(config.ClientLimit, config.MaxTracked),
+		senders:     newKeyLimiter(config.FromLimit, config.MaxTracked),
+		submissions: make(map[string]*tracked),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.mux.HandleFunc("/v1/typed-data", s.handleTypedData)
+	s.mux.HandleFunc("/v1/submit", s.handleSubmit)
+	s.mux.HandleFunc("/v1/status/", s.handleStatus)
+	s.mux.HandleFunc("/v1/limits", s.handleLimits)
+	s.mux.HandleFunc("/v1/schemas/", s.handleSchema)
+	return s, nil
+}
+
+// Close stops waiting for the submissions in flight.
+func (s *Server) Close() {
+	s.cancel()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.cors(w, r) {
+		return
+	}
+	if r.Method == http.MethodPost && !s.clients.allow(s.config.ClientKey(r)) {
+		s.fail(w, http.StatusTooManyRequests, fmt.Errorf("Too many requests from this client"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// cors sets the CORS headers of an allowed origin and answers preflights.
+func (s *Server) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	allowed := ""
+	for _, o := range s.config.AllowedOrigins {
+		if o == "*" {
+			allowed = "*"
+			break
+		}
+		if strings.EqualFold(o, origin) {
+			allowed = origin
+		}
+	}
+	if allowed == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowed)
+	if allowed != "*" {
+		w.Header().Add("Vary", "Origin")
+	}
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (s *Server) handleTypedData(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req TypedDataRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	if req.Dapp == (common.Address{}) || req.From == (common.Address{}) || req.Method == "" {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("dapp, from and method are required"))
+		return
+	}
+	if !s.allowFrom(w, req.From) {
+		return
+	}
+	dapp, err := s.dapp(req.Dapp)
+	if err != nil {
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	m, params, err := resolveCall(dapp.ABI(), req.Method, req.Params)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.allowed(req.Dapp, m); err != nil {
+		s.fail(w, http.StatusForbidden, err)
+		return
+	}
+	payload, err := dapp.BuildForwardRequestWithOpts(r.Context(), nil, req.From, m.Sig, params...)
+	if err != nil {
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	s.reply(w, http.StatusOK, payload)
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req SubmitRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	if req.Payload == nil || req.Payload.Message == nil || len(req.Signature) == 0 {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("payload and signature are required"))
+		return
+	}
+	if !s.allowFrom(w, req.Payload.Message.From) {
+		return
+	}
+	dapp, err := s.dapp(req.Payload.Message.To)
+	if err != nil {
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	// the policy applies to the call the signature covers, not the payload's Method
+	m, err := calledMethod(dapp.ABI(), req.Payload.Message.Data)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.allowed(dapp.Address(), m); err != nil {
+		s.fail(w, http.StatusForbidden, err)
+		return
+	}
+	req.Payload.Method = m.Sig
+	if !s.reserveSlot() {
+		s.fail(w, http.StatusServiceUnavailable, fmt.Errorf("Too many submissions in flight"))
+		return
+	}
+	id, err := newID()
+	if err != nil {
+		s.releaseSlot("", nil)
+		s.fail(w, http.StatusInternalServerError, err)
+		return
+	}
+	sub, err := s.b.SubmitSignedContext(r.Context(), req.Payload, req.Signature)
+	if err != nil {
+		s.releaseSlot("", nil)
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	t := &tracked{sub: sub}
+	s.releaseSlot(id, t)
+	go s.wait(t)
+	s.reply(w, http.StatusAccepted, s.status(id, t))
+}
+
+// wait drives the status of a submission until it is final.
+func (s *Server) wait(t *tracked) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.WaitTimeout)
+	defer cancel()
+	_, receipt, err := t.sub.WaitWithOpts(ctx, &metax.WaitOpts{Confirmations: s.config.Confirmations})
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.err = err
+	if receipt != nil && receipt.BlockNumber != nil {
+		t.blockNumber = receipt.BlockNumber.Uint64()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.WithError(err).Warnf("Wait for %s failed", t.sub.TxHash.Hex())
+	}
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/status/")
+	s.mu.Lock()
+	t, ok := s.submissions[id]
+	s.mu.Unlock()
+	if !ok {
+		s.fail(w, http.StatusNotFound, fmt.Errorf("Submission %q not found", id))
+		return
+	}
+	s.reply(w, http.StatusOK, s.status(id, t))
+}
+
+func (s *Server) status(id string, t *tracked) *StatusResponse {
+	resp := &StatusResponse{
+		ID:          id,
+		TxHash:      t.sub.TxHash,
+		Status:      t.sub.Status(),
+		Relayer:     t.sub.Relayer,
+		SubmittedAt: t.sub.SubmittedAt,
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	resp.BlockNumber = t.blockNumber
+	if t.err != nil {
+		resp.Error = t.err.Error()
+	}
+	return resp
+}
+
+func (s *Server) handleLimits(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	query := r.URL.Query()
+	dappAddress, from, method := query.Get("dapp"), query.Get("from"), query.Get("method")
+	if !common.IsHexAddress(dappAddress) || !common.IsHexAddress(from) || method == "" {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("dapp and from addresses and method are required"))
+		return
+	}
+	dapp, err := s.dapp(common.HexToAddress(dappAddress))
+	if err != nil {
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	m, err := namedMethod(dapp.ABI(), method)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.allowed(dapp.Address(), m); err != nil {
+		s.fail(w, http.StatusForbidden, err)
+		return
+	}
+	resp, err := dapp.CheckLimitsContext(r.Context(), common.HexToAddress(from).Hex(), m.Sig)
+	if err != nil && resp == nil {
+		s.fail(w, statusOf(err), err)
+		return
+	}
+	s.reply(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	name := path.Base(r.URL.Path)
+	schema, err := schemas.ReadFile("schemas/" + name)
+	if err != nil {
+		s.fail(w, http.StatusNotFound, fmt.Errorf("Schema %q not found", name))
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(schema)
+}
+
+// allowFrom answers 429 once the signer from ran out of requests.
+func (s *Server) allowFrom(w http.ResponseWriter, from common.Address) bool {
+	if s.senders.allow(from.Hex()) {
+		return true
+	}
+	s.fail(w, http.StatusTooManyRequests, fmt.Errorf("Too many requests for %s", from.Hex()))
+	return false
+}
+
+// calledMethod is the method of the DAPP ABI data calls, by its selector.
+func calledMethod(contractABI abi.ABI, data string) (*abi.Method, error) {
+	calldata, err := hexutil.Decode(data)
+	if err != nil || len(calldata) < 4 {
+		return nil, fmt.Errorf("%w: forward request data has no selector", metax.ErrMethodNotFound)
+	}
+	m, err := contractABI.MethodById(calldata[:4])
+	if err != nil {
+		return nil, fmt.Errorf("%w: selector %s", metax.ErrMethodNotFound, hexutil.Encode(calldata[:4]))
+	}
+	return m, nil
+}
+
+// dapp is the registered DAPP at address, if the policy serves it.
+func (s *Server) dapp(address common.Address) (*metax.DappHandle, error) {
+	dapp, ok := s.b.Dapp(address)
+	if !ok {
+		return nil, fmt.Errorf("%w: DAPP %s", ErrNotAllowed, address.Hex())
+	}
+	if s.config.Policy != nil {
+		if _, ok := s.config.Policy[address]; !ok {
+			return nil, fmt.Errorf("%w: DAPP %s", ErrNotAllowed, address.Hex())
+		}
+	}
+	return dapp, nil
+}
+
+func (s *Server) allowed(dapp common.Address, m *abi.Method) error {
+	if s.config.Policy == nil {
+		return nil
+	}
+	methods := s.config.Policy[dapp]
+	if len(methods) == 0 {
+		return nil
+	}
+	for _, method := range methods {
+		if methodMatches(m, method) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: method %s of %s", ErrNotAllowed, m.Sig, dapp.Hex())
+}
+
+func methodMatches(m *abi.Method, method string) bool {
+	method = strings.Join(strings.Fields(method), "")
+	return method == m.Sig || method == m.Name || method == m.RawName || strings.EqualFold(method, hexutil.Encode(m.ID))
+}
+
+// namedMethod finds the method without params, so an overloaded name is
+// ambiguous.
+func namedMethod(contractABI abi.ABI, method string) (*abi.Method, error) {
+	var found *abi.Method
+	for _, candidate := range contractABI.Methods {
+		candidate := candidate
+		if !methodMatches(&candidate, method) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: %s, use the signature or selector", metax.ErrAmbiguousMethod, method)
+		}
+		found = &candidate
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", metax.ErrMethodNotFound, method)
+	}
+	return found, nil
+}
+
+// resolveCall finds the method whose inputs accept params, so overloads may
+// be called by name.
+func resolveCall(contractABI abi.ABI, method string, raw []json.RawMessage) (*abi.Method, []interface{}, error) {
+	var found *abi.Method
+	var params []interface{}
+	var lastErr error
+	for _, candidate := range contractABI.Methods {
+		candidate := candidate
+		if !methodMatches(&candidate, method) {
+			continue
+		}
+		decoded, err := decodeParams(candidate.Inputs, raw)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", candidate.Sig, err)
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("%w: %s, use the signature", metax.ErrAmbiguousMethod, method)
+		}
+		found, params = &candidate, decoded
+	}
+	if found != nil {
+		return found, params, nil
+	}
+	if lastErr != nil {
+		return nil, nil, lastErr
+	}
+	return nil, nil, fmt.Errorf("%w: %s", metax.ErrMethodNotFound, method)
+}
+
+// reserveSlot takes one of the MaxTracked submission slots before
+// submitting, so concurrent submits cannot exceed it.
+func (s *Server) reserveSlot() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked()
+	if len(s.submissions)+s.submitting >= s.config.MaxTracked {
+		return false
+	}
+	s.submitting++
+	return true
+}
+
+// releaseSlot ends a submit, keeping t under id when it was relayed.
+func (s *Server) releaseSlot(id string, t *tracked) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.submitting--
+	if t != nil {
+		s.submissions[id] = t
+	}
+}
+
+func (s *Server) expireLocked() {
+	for id, t := range s.submissions {
+		if time.Since(t.sub.SubmittedAt) > s.config.StatusTTL {
+			delete(s.submissions, id)
+		}
+	}
+}
+
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, s.config.MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("Invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func (s *Server) reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.WithError(err).Error("Encode response failed")
+	}
+}
+
+func (s *Server) fail(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.logger.WithError(err).Error("Request failed")
+	} else {
+		s.logger.WithError(err).Debug("Request refused")
+	}
+	s.reply(w, status, &ErrorResponse{Error: err.Error()})
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+// statusOf maps metax errors to HTTP statuses.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, metax.ErrSignatureInvalid), errors.Is(err, metax.ErrDeadlineExpired),
+		errors.Is(err, metax.ErrDeadlineInvalid), errors.Is(err, metax.ErrDappMismatch),
+		errors.Is(err, metax.ErrMethodNotFound), errors.Is(err, metax.ErrAmbiguousMethod),
+		errors.Is(err, metax.ErrMethodMismatch), errors.Is(err, metax.ErrApiIdNotFound),
+		errors.Is(err, metax.ErrForwarderNotTrusted), errors.Is(err, metax.ErrSimulationReverted),
+		errors.Is(err, metax.ErrFeeTokenNotAllowed), errors.Is(err, metax.ErrFeeAllowance),
+		errors.Is(err, metax.ErrFeeBalance):
+		return http.StatusBadRequest
+	case errors.Is(err, metax.ErrNonceUnavailable):
+		return http.StatusConflict
+	case errors.Is(err, metax.ErrLimitExhausted):
+		return http.StatusTooManyRequests
+	case errors.Is(err, metax.ErrRelayerRejected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hexutil.Encode(id)[2:], nil
+}
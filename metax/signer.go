@@ -2,14 +2,36 @@ package metax
 
 import (
 	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
 	"io/ioutil"
+	"math/big"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip39"
 )
 
+// TypedDataSigner signs EIP-712 typed data on behalf of GetAddress. The
+// returned signature is 65 bytes with v in {27, 28}.
+type TypedDataSigner interface {
+	GetAddress() common.Address
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
+// TxSigner signs transactions sent from GetAddress, as the self relayer does.
+type TxSigner interface {
+	GetAddress() common.Address
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// Signer is the in-memory TypedDataSigner holding a raw private key.
 type Signer struct {
 	Address common.Address
 	key     *ecdsa.PrivateKey
@@ -20,10 +42,14 @@ func NewSigner(privKey string) (*Signer, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewSignerFromKey(key), nil
+}
+
+func NewSignerFromKey(key *ecdsa.PrivateKey) *Signer {
 	return &Signer{
 		key:     key,
 		Address: crypto.PubkeyToAddress(key.PublicKey),
-	}, nil
+	}
 }
 
 func NewSignerFromPath(privPath string) (*Signer, error) {
@@ -34,6 +60,72 @@ func NewSignerFromPath(privPath string) (*Signer, error) {
 	return NewSigner(strings.TrimSpace(string(f)))
 }
 
+// NewSignerFromMnemonic derives the key at the BIP-32 derivation path, e.g.
+// accounts.DefaultBaseDerivationPath "m/44'/60'/0'/0/0", from the BIP-39
+// seed of mnemonic and passphrase. The mnemonic must be made of English
+// word list words and carry a valid checksum, so a mistyped word is refused
+// rather than deriving another key.
+func NewSignerFromMnemonic(mnemonic string, passphrase string, path string) (*Signer, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+		return nil, fmt.Errorf("Invalid mnemonic: %w", err)
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(bip39.NewSeed(mnemonic, passphrase), derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerFromKey(key), nil
+}
+
+// deriveKey walks BIP-32 private child derivation from the master key of seed.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	curveN := crypto.S256().Params().N
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(curveN) >= 0 {
+		return nil, fmt.Errorf("Invalid master key derived from seed")
+	}
+
+	for _, index := range path {
+		data := make([]byte, 0, 37)
+		if index >= 0x80000000 {
+			data = append(data, 0)
+			data = append(data, common.LeftPadBytes(key.Bytes(), 32)...)
+		} else {
+			priv, err := crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, crypto.CompressPubkey(&priv.PublicKey)...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveN) >= 0 {
+			return nil, fmt.Errorf("Invalid child key at index %v", index)
+		}
+		key = new(big.Int).Mod(new(big.Int).Add(tweak, key), curveN)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("Invalid child key at index %v", index)
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+}
+
+func (s *Signer) GetAddress() common.Address {
+	return s.Address
+}
+
 func (s *Signer) GetPublicKey() []byte {
 	return crypto.FromECDSAPub(&s.key.PublicKey)
 }
@@ -52,3 +144,7 @@ func (s *Signer) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
 	}
 	return sig, nil
 }
+
+func (s *Signer) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
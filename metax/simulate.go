@@ -0,0 +1,164 @@
+package metax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
+)
+
+// DefaultSimulationRelayer is the caller of simulated forwarder executions
+// when TransactOpts.SimulationFrom is not set. Like a Biconomy relayer it is
+// an account without code.
+var DefaultSimulationRelayer = common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+// SimulationResult is the outcome of a pre-flight of a signed forward request.
+type SimulationResult struct {
+	// From is the relayer-like account the calls were made from
+	From common.Address
+	// Verified is set when verifyEIP712 / verifyPersonalSign accepted the request
+	Verified bool
+	// Success is set when executeEIP712 / executePersonalSign did not revert
+	Success bool
+	// ReturnData is what the dapp returned to the forwarder
+	ReturnData []byte
+	// RevertData and RevertReason describe the failing call, RevertReason
+	// being empty when the data is not an Error(string)
+	RevertData   []byte
+	RevertReason string
+}
+
+// Err is nil for a successful simulation and a *SimulationError otherwise.
+func (r *SimulationResult) Err() error {
+	if r.Verified && r.Success {
+		return nil
+	}
+	return &SimulationError{Result: r}
+}
+
+func (b *Bcnmy) Simulate(metaTxMessage *MetaTxMessage, signature []byte) (*SimulationResult, error) {
+	return b.SimulateContext(b.ctx, metaTxMessage, signature)
+}
+
+func (b *Bcnmy) SimulateContext(ctx context.Context, metaTxMessage *MetaTxMessage, signature []byte) (*SimulationResult, error) {
+	return b.SimulateWithOpts(ctx, nil, metaTxMessage, signature)
+}
+
+// SimulateWithOpts runs the forwarder's signature check and then the forwarded
+// call through eth_call, without sending anything to Biconomy. A reverted
+// check or call is reported in the result, the error is only set when the
+// chain could not be queried.
+func (b *Bcnmy) SimulateWithOpts(ctx context.Context, opts *TransactOpts, metaTxMessage *MetaTxMessage, signature []byte) (*SimulationResult, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, err
+	}
+	req, err := metaTxMessage.ForwardRequest()
+	if err != nil {
+		return nil, err
+	}
+	forwarderABI, err := forwarder.ForwarderMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	var verifyData, executeData []byte
+	switch opts.signatureType() {
+	case SignatureEIP712Type:
+		typedData := b.forwardTypedData(metaTxMessage)
+		domainSeparator, err := typedData.HashStruct(EIP712DomainType, typedData.Domain.Map())
+		if err != nil {
+			return nil, err
+		}
+		separator := common.BytesToHash(domainSeparator)
+		if verifyData, err = forwarderABI.Pack("verifyEIP712", req, separator, signature); err != nil {
+			return nil, err
+		}
+		if executeData, err = forwarderABI.Pack("executeEIP712", req, separator, signature); err != nil {
+			return nil, err
+		}
+	case SignaturePersonalType:
+		if verifyData, err = forwarderABI.Pack("verifyPersonalSign", req, signature); err != nil {
+			return nil, err
+		}
+		if executeData, err = forwarderABI.Pack("executePersonalSign", req, signature); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Signature type not supported: %s", opts.signatureType())
+	}
+
+	result := &SimulationResult{From: opts.simulationFrom()}
+	if _, reverted, err := b.simulateCall(ctx, result, verifyData); err != nil || reverted {
+		return result, err
+	}
+	result.Verified = true
+
+	out, reverted, err := b.simulateCall(ctx, result, executeData)
+	if err != nil || reverted {
+		return result, err
+	}
+	// executeEIP712 and executePersonalSign both return (bool success, bytes ret)
+	ret, err := forwarderABI.Unpack("executeEIP712", out)
+	if err != nil {
+		return nil, fmt.Errorf("Unpack forwarder execution result failed: %v", err)
+	}
+	result.Success = ret[0].(bool)
+	result.ReturnData = ret[1].([]byte)
+	if !result.Success {
+		result.RevertData = result.ReturnData
+		result.RevertReason, _ = abi.UnpackRevert(result.ReturnData)
+	}
+	return result, nil
+}
+
+// simulateCall calls the forwarder with data and records a revert in result.
+func (b *Bcnmy) simulateCall(ctx context.Context, result *SimulationResult, data []byte) ([]byte, bool, error) {
+	out, err := b.ethClient.CallContract(ctx, ethereum.CallMsg{
+		From: result.From,
+		To:   &b.trustedForwarder.Address,
+		Data: data,
+	}, nil)
+	if err == nil {
+		return out, false, nil
+	}
+	revertData, ok := revertDataOf(err)
+	if !ok {
+		b.logger.WithError(err).Error("Simulate forwarder call failed")
+		return nil, false, err
+	}
+	result.RevertData = revertData
+	if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+		result.RevertReason = reason
+	} else if len(revertData) == 0 {
+		result.RevertReason = err.Error()
+	}
+	return nil, true, nil
+}
+
+// revertDataOf extracts the revert data of an eth_call error, reporting
+// false when err is not an execution revert.
+func revertDataOf(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		switch data := dataErr.ErrorData().(type) {
+		case string:
+			if revertData, err := hexutil.Decode(data); err == nil {
+				return revertData, true
+			}
+		case []byte:
+			return data, true
+		}
+	}
+	if strings.Contains(err.Error(), "execution reverted") {
+		return nil, true
+	}
+	return nil, false
+}
@@ -0,0 +1,198 @@
+package metax
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxStatus is the lifecycle state of a relayed meta transaction.
+type TxStatus string
+
+const (
+	// Biconomy accepted the request and returned a transaction hash
+	TxStatusRelayed TxStatus = "relayed"
+	// the node knows the transaction, it is not mined yet
+	TxStatusPending TxStatus = "pending"
+	// a successful receipt exists, confirmations are still missing
+	TxStatusMined TxStatus = "mined"
+	// the receipt reached the requested confirmations
+	TxStatusConfirmed TxStatus = "confirmed"
+	// Biconomy rejected the request or the transaction reverted
+	TxStatusFailed TxStatus = "failed"
+	// the node forgot the pending transaction
+	TxStatusDropped TxStatus = "dropped"
+	// the receipt left the canonical chain, the transaction is waited for again
+	TxStatusReorged TxStatus = "reorged"
+)
+
+// Final reports whether no transition follows s.
+func (s TxStatus) Final() bool {
+	return s == TxStatusConfirmed || s == TxStatusFailed || s == TxStatusDropped
+}
+
+// StatusUpdate is a status transition of a Submission.
+type StatusUpdate struct {
+	Status  TxStatus
+	TxHash  common.Hash
+	Receipt *types.Receipt
+	Err     error
+	At      time.Time
+}
+
+// headerReader is implemented by *ethclient.Client and the simulated backend.
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Submission is the handle of a meta transaction handed to Biconomy. Status
+// transitions after TxStatusRelayed are observed while Wait runs.
+type Submission struct {
+	TxHash      common.Hash
+	Request     *MetaTxRequest
+	Message     *MetaTxMessage
+	Response    *MetaTxResponse
+	SubmittedAt time.Time
+	// Fee is the quote of an ERC20 paid request, nil when the DAPP pays
+	Fee *FeeQuote
+	// Relayer is the Name of the Relayer that took the request
+	Relayer string
+
+	b        *Bcnmy
+	onStatus func(StatusUpdate)
+	nonce    *NonceReservation
+
+	mu      sync.Mutex
+	updates []StatusUpdate
+	subs    []chan StatusUpdate
+}
+
+func newSubmission(b *Bcnmy, opts *TransactOpts, req *MetaTxRequest, metaTxMessage *MetaTxMessage) *Submission {
+	s := &Submission{
+		Request:     req,
+		Message:     metaTxMessage,
+		SubmittedAt: time.Now(),
+		b:           b,
+	}
+	if opts != nil {
+		s.onStatus = opts.OnStatus
+	}
+	return s
+}
+
+func (s *Submission) response() *MetaTxResponse {
+	if s == nil {
+		return nil
+	}
+	return s.Response
+}
+
+// Status is the latest observed status.
+func (s *Submission) Status() TxStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.updates) == 0 {
+		return ""
+	}
+	return s.updates[len(s.updates)-1].Status
+}
+
+// Updates returns a channel replaying the transitions so far and receiving
+// the following ones. It is closed after a final status. Reorgs publish
+// transitions again, a reader falling more than updatesBuffer behind misses
+// them; Status still tells the latest.
+func (s *Submission) Updates() <-chan StatusUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan StatusUpdate, len(s.updates)+updatesBuffer)
+	for _, update := range s.updates {
+		ch <- update
+	}
+	if len(s.updates) > 0 && s.updates[len(s.updates)-1].Status.Final() {
+		close(ch)
+		return ch
+	}
+	s.subs = append(s.subs, ch)
+	return ch
+}
+
+// updatesBuffer is the capacity of Updates channels beyond the replayed
+// transitions, enough for every status once.
+const updatesBuffer = 16
+
+// publish records a transition, ignoring statuses already reached since the
+// last reorg.
+func (s *Submission) publish(status TxStatus, receipt *types.Receipt, err error) {
+	s.mu.Lock()
+	for i := len(s.updates) - 1; i >= 0; i-- {
+		update := s.updates[i]
+		// a reorg is only ignored right after another
+		if update.Status.Final() || (update.Status == status && (status != TxStatusReorged || i == len(s.updates)-1)) {
+			s.mu.Unlock()
+			return
+		}
+		if update.Status == TxStatusReorged {
+			break
+		}
+	}
+	update := StatusUpdate{Status: status, TxHash: s.TxHash, Receipt: receipt, Err: err, At: time.Now()}
+	s.updates = append(s.updates, update)
+	for _, ch := range s.subs {
+		select {
+		case ch <- update:
+		default:
+			s.b.logger.Warnf("Transaction %s %s not delivered to a slow reader", s.TxHash.Hex(), status)
+		}
+		if status.Final() {
+			close(ch)
+		}
+	}
+	if status.Final() {
+		s.subs = nil
+	}
+	s.mu.Unlock()
+
+	s.b.logger.Debugf("Transaction %s %s", s.TxHash.Hex(), status)
+	switch status {
+	case TxStatusMined, TxStatusConfirmed:
+		s.nonce.Done()
+	case TxStatusFailed:
+		s.nonce.Fail()
+	case TxStatusDropped:
+		s.nonce.Stale()
+	}
+	if s.onStatus != nil {
+		s.onStatus(update)
+	}
+}
+
+// Wait blocks until the transaction has confirmations blocks on top of and
+// including its own, it failed or it was dropped. A reverted transaction is
+// returned with its receipt and a nil error, as RawTransact always did.
+func (s *Submission) Wait(ctx context.Context, confirmations uint64) (*types.Transaction, *types.Receipt, error) {
+	return s.WaitWithOpts(ctx, &WaitOpts{Confirmations: confirmations})
+}
+
+// transactionByHash loads a mined transaction, retrying since some nodes lag
+// behind their receipts.
+func (b *Bcnmy) transactionByHash(ctx context.Context, txReader transactionReader, txHash common.Hash) (*types.Transaction, error) {
+	retries := 5
+	for {
+		retries -= 1
+		tx, _, err := txReader.TransactionByHash(ctx, txHash)
+		if err == nil {
+			return tx, nil
+		}
+		b.logger.Errorf("Checking TransactionByHash failed: %v, retries: %v", err, retries)
+		if retries < 0 {
+			return nil, err
+		}
+		if err := sleepContext(ctx, time.Second*b.sleepTimeSec); err != nil {
+			return nil, err
+		}
+	}
+}
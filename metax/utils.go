@@ -6,7 +6,6 @@ import (
 	"errors"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
-	"log"
 	"math/big"
 	"time"
 
@@ -14,12 +13,15 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
 )
 
-// WaitMined waits for tx to be mined on the blockchain.
-// It stops waiting when the context is canceled.
+// WaitMined waits for tx to be mined on the blockchain, returning the first
+// receipt seen. It stops waiting when the context is canceled. Use
+// Bcnmy.WaitConfirmed to wait for a depth and follow reorgs.
 func WaitMined(ctx context.Context, b bind.DeployBackend, txHash common.Hash) (*types.Receipt, error) {
-	queryTicker := time.NewTicker(time.Second)
+	logger := logrus.WithField("metax", "utils")
+	queryTicker := time.NewTicker(DefaultPollInterval)
 	defer queryTicker.Stop()
 
 	for {
@@ -29,9 +31,9 @@ func WaitMined(ctx context.Context, b bind.DeployBackend, txHash common.Hash) (*
 		}
 
 		if errors.Is(err, ethereum.NotFound) {
-			log.Printf("Transaction %s not yet mined\n", txHash)
+			logger.Debugf("Transaction %s not yet mined", txHash.Hex())
 		} else {
-			log.Printf("Receipt retrieval failed: %v\n", err)
+			logger.WithError(err).Error("Receipt retrieval failed")
 		}
 
 		// Wait for the next round.
@@ -43,6 +45,18 @@ func WaitMined(ctx context.Context, b bind.DeployBackend, txHash common.Hash) (*
 	}
 }
 
+// sleepContext pauses for d, returning early with the context error once ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func ConvertToJsonStr(obj interface{}) string {
 	jsonStr, err := json.MarshalIndent(obj, "", " ")
 	if err != nil {
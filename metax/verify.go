@@ -0,0 +1,105 @@
+package metax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// RecoverAddress recovers the signer of digest from a 65 bytes [R || S || V]
+// signature, V being 0/1 or 27/28. Malleable high-s signatures are rejected
+// like the forwarder's ECDSA library does.
+func RecoverAddress(digest []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: length %v, want %v", ErrSignatureInvalid, len(signature), crypto.SignatureLength)
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(sig[crypto.RecoveryIDOffset], r, s, true) {
+		return common.Address{}, fmt.Errorf("%w: invalid v, r or high s value", ErrSignatureInvalid)
+	}
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// VerifyMetaTx checks a frontend supplied forward request before it is
+// relayed: from must be metaTxMessage.From, To the configured dapp, Deadline
+// valid for the DeadlinePolicy, and signature must recover to From over the configured
+// forwarder domain. A signature made for another forwarder or chain recovers
+// to a different account and is rejected the same way.
+func (b *Bcnmy) VerifyMetaTx(opts *TransactOpts, from string, signature []byte, metaTxMessage *MetaTxMessage) error {
+	return b.VerifyMetaTxContext(b.ctx, opts, from, signature, metaTxMessage)
+}
+
+func (b *Bcnmy) VerifyMetaTxContext(ctx context.Context, opts *TransactOpts, from string, signature []byte, metaTxMessage *MetaTxMessage) error {
+	return b.defaultDapp().VerifyMetaTxContext(ctx, opts, from, signature, metaTxMessage)
+}
+
+func (d *DappHandle) VerifyMetaTx(opts *TransactOpts, from string, signature []byte, metaTxMessage *MetaTxMessage) error {
+	return d.VerifyMetaTxContext(d.b.ctx, opts, from, signature, metaTxMessage)
+}
+
+func (d *DappHandle) VerifyMetaTxContext(ctx context.Context, opts *TransactOpts, from string, signature []byte, metaTxMessage *MetaTxMessage) error {
+	b := d.b
+	if err := b.ensureChain(ctx); err != nil {
+		return err
+	}
+	if err := d.verifyMetaTx(opts, from, signature, metaTxMessage); err != nil {
+		b.logger.WithError(err).Error("Verify MetaTxMessage failed")
+		return err
+	}
+	return nil
+}
+
+func (d *DappHandle) verifyMetaTx(opts *TransactOpts, from string, signature []byte, metaTxMessage *MetaTxMessage) error {
+	if metaTxMessage.BatchId == nil || metaTxMessage.BatchNonce == nil || metaTxMessage.Deadline == nil {
+		return fmt.Errorf("%w: BatchId, BatchNonce and Deadline are required", ErrSignatureInvalid)
+	}
+	if !common.IsHexAddress(from) || common.HexToAddress(from) != metaTxMessage.From {
+		return fmt.Errorf("%w: from %s not match MetaTxMessage.From %s", ErrSignatureInvalid, from, metaTxMessage.From.Hex())
+	}
+	if metaTxMessage.To != d.address {
+		return fmt.Errorf("%w: MetaTxMessage.To %s, dapp %s", ErrDappMismatch, metaTxMessage.To.Hex(), d.address.Hex())
+	}
+	if err := d.b.ValidateDeadline(metaTxMessage.Deadline); err != nil {
+		return err
+	}
+
+	var digest []byte
+	switch opts.signatureType() {
+	case SignatureEIP712Type:
+		hash, _, err := apitypes.TypedDataAndHash(d.b.forwardTypedData(metaTxMessage))
+		if err != nil {
+			return err
+		}
+		digest = hash
+	case SignaturePersonalType:
+		hash, err := PersonalSignHash(metaTxMessage)
+		if err != nil {
+			return err
+		}
+		digest = accounts.TextHash(hash.Bytes())
+	default:
+		return fmt.Errorf("Signature type not supported: %s", opts.signatureType())
+	}
+	recovered, err := RecoverAddress(digest, signature)
+	if err != nil {
+		return err
+	}
+	if recovered != metaTxMessage.From {
+		return fmt.Errorf("%w: recovered %s, want %s, signed by another account or for another forwarder domain", ErrSignatureInvalid, recovered.Hex(), metaTxMessage.From.Hex())
+	}
+	return nil
+}
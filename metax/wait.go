@@ -0,0 +1,202 @@
+package metax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultPollInterval is the receipt polling interval of Wait.
+const DefaultPollInterval = time.Second
+
+// headSubscriber is implemented by *ethclient.Client, where it only works
+// over a websocket or IPC connection, and the simulated backend.
+type headSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// WaitOpts tunes WaitWithOpts, nil selects the defaults.
+type WaitOpts struct {
+	// Confirmations is the depth the receipt must reach, counting its own
+	// block, 1 (mined) when zero
+	Confirmations uint64
+	// PollInterval between receipt checks, the client's WithPollInterval when zero
+	PollInterval time.Duration
+	// NoSubscription disables waking up on SubscribeNewHead heads, only
+	// polling then
+	NoSubscription bool
+}
+
+func (o *WaitOpts) confirmations() uint64 {
+	if o == nil || o.Confirmations == 0 {
+		return 1
+	}
+	return o.Confirmations
+}
+
+// WaitConfirmed waits for any transaction like Submission.WaitWithOpts, e.g.
+// one relayed by an earlier process.
+func (b *Bcnmy) WaitConfirmed(ctx context.Context, txHash common.Hash, opts *WaitOpts) (*types.Transaction, *types.Receipt, error) {
+	if err := b.ensureChain(ctx); err != nil {
+		return nil, nil, err
+	}
+	s := &Submission{TxHash: txHash, b: b}
+	return s.WaitWithOpts(ctx, opts)
+}
+
+// WaitWithOpts blocks until the receipt is opts.Confirmations deep, the
+// transaction failed at that depth or was dropped. A receipt that disappears
+// or moves to another block is reported as TxStatusReorged and waited for
+// again; ErrTxDropped is returned once the node no longer knows the
+// transaction. Deep waits check the receipt block is still canonical before
+// confirming. Besides polling, new heads wake the waiter when the backend
+// supports SubscribeNewHead.
+func (s *Submission) WaitWithOpts(ctx context.Context, opts *WaitOpts) (*types.Transaction, *types.Receipt, error) {
+	if s.TxHash == (common.Hash{}) {
+		return nil, nil, fmt.Errorf("Submission was not relayed")
+	}
+	deployBackend, ok := s.b.ethClient.(bind.DeployBackend)
+	if !ok {
+		err := fmt.Errorf("Contract backend cannot fetch receipts")
+		s.b.logger.Error(err.Error())
+		return nil, nil, err
+	}
+	txReader, ok := s.b.ethClient.(transactionReader)
+	if !ok {
+		err := fmt.Errorf("Contract backend cannot fetch transactions")
+		s.b.logger.Error(err.Error())
+		return nil, nil, err
+	}
+	confirmations := opts.confirmations()
+	heads, _ := s.b.ethClient.(headerReader)
+	if confirmations > 1 && heads == nil {
+		err := fmt.Errorf("Contract backend cannot fetch headers")
+		s.b.logger.Error(err.Error())
+		return nil, nil, err
+	}
+
+	interval := s.b.pollInterval
+	if opts != nil && opts.PollInterval > 0 {
+		interval = opts.PollInterval
+	}
+	queryTicker := time.NewTicker(interval)
+	defer queryTicker.Stop()
+	// ends the head subscription with the wait
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	newHeads, subErr := s.subscribeHeads(ctx, opts)
+
+	seen := false
+	var mined *types.Receipt
+	for {
+		receipt, err := deployBackend.TransactionReceipt(ctx, s.TxHash)
+		switch {
+		case err == nil:
+			seen = true
+			if mined != nil && mined.BlockHash != receipt.BlockHash {
+				s.b.logger.Warnf("Transaction %s moved from block %s to %s", s.TxHash.Hex(), mined.BlockHash.Hex(), receipt.BlockHash.Hex())
+				s.publish(TxStatusReorged, receipt, nil)
+			}
+			mined = receipt
+			if receipt.Status == types.ReceiptStatusSuccessful {
+				s.publish(TxStatusMined, receipt, nil)
+			}
+			confirmed, err := s.confirmed(ctx, heads, receipt, confirmations)
+			if err != nil {
+				s.b.logger.WithError(err).Error("HeaderByNumber failed")
+				break
+			}
+			if !confirmed {
+				break
+			}
+			if receipt.Status == types.ReceiptStatusFailed {
+				s.publish(TxStatusFailed, receipt, nil)
+			} else {
+				s.publish(TxStatusConfirmed, receipt, nil)
+			}
+			tx, err := s.b.transactionByHash(ctx, txReader, s.TxHash)
+			return tx, receipt, err
+		case errors.Is(err, ethereum.NotFound):
+			if mined != nil {
+				s.b.logger.Warnf("Transaction %s receipt in block %s reorged out", s.TxHash.Hex(), mined.BlockHash.Hex())
+				s.publish(TxStatusReorged, nil, nil)
+				mined = nil
+			}
+			_, _, err := txReader.TransactionByHash(ctx, s.TxHash)
+			if err == nil {
+				seen = true
+				s.publish(TxStatusPending, nil, nil)
+			} else if errors.Is(err, ethereum.NotFound) && seen {
+				err := fmt.Errorf("%w: %s", ErrTxDropped, s.TxHash.Hex())
+				s.publish(TxStatusDropped, nil, err)
+				return nil, nil, err
+			}
+			s.b.logger.Debugf("Transaction %s not yet mined", s.TxHash.Hex())
+		default:
+			s.b.logger.WithError(err).Error("Receipt retrieval failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-queryTicker.C:
+		case <-newHeads:
+		case err := <-subErr:
+			s.b.logger.WithError(err).Warn("New head subscription failed, polling only")
+			newHeads, subErr = nil, nil
+		}
+	}
+}
+
+// subscribeHeads returns the channel of new heads, nil when the backend or
+// its connection cannot subscribe. The subscription ends with ctx.
+func (s *Submission) subscribeHeads(ctx context.Context, opts *WaitOpts) (<-chan *types.Header, <-chan error) {
+	subscriber, ok := s.b.ethClient.(headSubscriber)
+	if !ok || (opts != nil && opts.NoSubscription) {
+		return nil, nil
+	}
+	newHeads := make(chan *types.Header, 16)
+	sub, err := subscriber.SubscribeNewHead(ctx, newHeads)
+	if err != nil {
+		s.b.logger.WithError(err).Debug("SubscribeNewHead unavailable, polling only")
+		return nil, nil
+	}
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+	return newHeads, sub.Err()
+}
+
+// confirmed reports whether receipt is confirmations deep. Deep receipts must
+// also still be in the canonical block at their height.
+func (s *Submission) confirmed(ctx context.Context, heads headerReader, receipt *types.Receipt, confirmations uint64) (bool, error) {
+	if confirmations <= 1 {
+		return true, nil
+	}
+	head, err := heads.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	depth := new(big.Int).Sub(head.Number, receipt.BlockNumber)
+	if depth.Sign() < 0 || depth.Uint64()+1 < confirmations {
+		return false, nil
+	}
+	canonical, err := heads.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return false, err
+	}
+	if canonical.Hash() != receipt.BlockHash {
+		s.b.logger.Warnf("Transaction %s block %s is no longer canonical", s.TxHash.Hex(), receipt.BlockHash.Hex())
+		s.publish(TxStatusReorged, receipt, nil)
+		return false, nil
+	}
+	return true, nil
+}
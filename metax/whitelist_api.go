@@ -2,6 +2,7 @@ package metax
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -43,6 +44,10 @@ type PatchProxyContractsRequest struct {
 }
 
 func (b *Bcnmy) AddDestinationAddresses(data *AddDestinationRequest) (*AddDestinationResponse, error) {
+	return b.AddDestinationAddressesContext(b.ctx, data)
+}
+
+func (b *Bcnmy) AddDestinationAddressesContext(ctx context.Context, data *AddDestinationRequest) (*AddDestinationResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -52,7 +57,7 @@ func (b *Bcnmy) AddDestinationAddresses(data *AddDestinationRequest) (*AddDestin
 		b.logger.WithError(err).Error("json marshal `AddDestinationRequest` data failed")
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, AddDestinationAddressesURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(AddDestinationAddressesPath), bytes.NewBuffer(body))
 	if err != nil {
 		b.logger.WithError(err).Error("AddDestinationAddresses NewRequest failed")
 		return nil, err
@@ -75,6 +80,10 @@ func (b *Bcnmy) AddDestinationAddresses(data *AddDestinationRequest) (*AddDestin
 }
 
 func (b *Bcnmy) AddProxyContracts(data *AddProxyContractsRequest) (*ProxyContractsResponse, error) {
+	return b.AddProxyContractsContext(b.ctx, data)
+}
+
+func (b *Bcnmy) AddProxyContractsContext(ctx context.Context, data *AddProxyContractsRequest) (*ProxyContractsResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -85,7 +94,7 @@ func (b *Bcnmy) AddProxyContracts(data *AddProxyContractsRequest) (*ProxyContrac
 		b.logger.WithError(err).Error("json marshal `AddProxyContractsRequest` data failed")
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, ProxyContractsURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoints.APIURL(ProxyContractsPath), bytes.NewBuffer(body))
 	if err != nil {
 		b.logger.WithError(err).Error("AddProxyContracts NewRequest failed")
 		return nil, err
@@ -108,6 +117,10 @@ func (b *Bcnmy) AddProxyContracts(data *AddProxyContractsRequest) (*ProxyContrac
 }
 
 func (b *Bcnmy) PatchProxyContracts(data *PatchProxyContractsRequest) (*ProxyContractsResponse, error) {
+	return b.PatchProxyContractsContext(b.ctx, data)
+}
+
+func (b *Bcnmy) PatchProxyContractsContext(ctx context.Context, data *PatchProxyContractsRequest) (*ProxyContractsResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
@@ -118,7 +131,7 @@ func (b *Bcnmy) PatchProxyContracts(data *PatchProxyContractsRequest) (*ProxyCon
 		b.logger.WithError(err).Error("json marshal `PatchProxyContractsRequest` data failed")
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPatch, ProxyContractsURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, b.endpoints.APIURL(ProxyContractsPath), bytes.NewBuffer(body))
 	if err != nil {
 		b.logger.WithError(err).Error("PatchProxyContracts NewRequest failed")
 		return nil, err
@@ -141,11 +154,15 @@ func (b *Bcnmy) PatchProxyContracts(data *PatchProxyContractsRequest) (*ProxyCon
 }
 
 func (b *Bcnmy) GetProxyContracts() (*GetProxyContractsResponse, error) {
+	return b.GetProxyContractsContext(b.ctx)
+}
+
+func (b *Bcnmy) GetProxyContractsContext(ctx context.Context) (*GetProxyContractsResponse, error) {
 	bodyCh := make(chan []byte)
 	errorCh := make(chan error)
 	defer close(bodyCh)
 	defer close(errorCh)
-	req, err := http.NewRequest(http.MethodGet, ProxyContractsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoints.APIURL(ProxyContractsPath), nil)
 	if err != nil {
 		b.logger.WithError(err).Error("GetProxyContracts NewRequest failed")
 		return nil, err
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+type fakeMetaAPI struct {
+	mu      sync.Mutex
+	listAPI []metax.MetaAPIInfo
+	calls   int32
+	delay   time.Duration
+	fail    bool
+}
+
+func (f *fakeMetaAPI) set(listAPI ...metax.MetaAPIInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listAPI = listAPI
+}
+
+func (f *fakeMetaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		http.Error(w, "unavailable", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(metax.MetaAPIResponse{Flag: 143, ListAPI: f.listAPI})
+}
+
+func TestAPIIDRefreshOnMiss(t *testing.T) {
+	metaAPI := &fakeMetaAPI{delay: 100 * time.Millisecond}
+	metaAPI.set(metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"})
+	mux := http.NewServeMux()
+	mux.Handle(metax.MetaAPIPath, metaAPI)
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true, Message: r.URL.Query().Get("apiId")})
+	})
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	// concurrent first uses share a single load
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.CheckLimitsContext(context.Background(), uniswapDapp, "transfer")
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&metaAPI.calls))
+
+	// a method added on the dashboard is picked up by the miss
+	metaAPI.set(
+		metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+		metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "approve", ID: "api-approve", MetaTxLimitStatus: 1},
+	)
+	time.Sleep(2 * time.Second)
+	info, err := b.MethodInfo(context.Background(), common.HexToAddress(offlineDapp), "approve")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-approve", info.ID)
+	assert.Equal(t, 1, info.MetaTxLimitStatus)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&metaAPI.calls))
+
+	// misses right after a load do not hit the meta API again
+	_, err = b.MethodInfo(context.Background(), common.HexToAddress(offlineDapp), "unknown")
+	assert.ErrorIs(t, err, metax.ErrApiIdNotFound)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&metaAPI.calls))
+}
+
+func TestAPIIDTTL(t *testing.T) {
+	metaAPI := &fakeMetaAPI{}
+	metaAPI.set(metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"})
+	mux := http.NewServeMux()
+	mux.Handle(metax.MetaAPIPath, metaAPI)
+	b, server, err := buildOfflineBcnmy(mux, metax.WithAPIIDTTL(200*time.Millisecond))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp := common.HexToAddress(offlineDapp)
+
+	info, err := b.MethodInfo(context.Background(), dapp, "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-transfer", info.ID)
+
+	metaAPI.set(metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer-v2"})
+	info, err = b.MethodInfo(context.Background(), dapp, "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-transfer", info.ID)
+
+	time.Sleep(300 * time.Millisecond)
+	info, err = b.MethodInfo(context.Background(), dapp, "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-transfer-v2", info.ID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&metaAPI.calls))
+
+	_, err = metax.New("test-api-key", metax.WithAPIIDTTL(-time.Second))
+	assert.NotNil(t, err)
+}
+
+func TestAPIIDDashboardUpdates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.AddMethodPath, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		resp := metax.AddMethodResponse{Code: 200}
+		resp.ApiIds = append(resp.ApiIds, struct {
+			ApiId  string `json:"apiId"`
+			Method string `json:"method"`
+			Name   string `json:"name"`
+		}{ApiId: "api-" + r.Form.Get("method"), Method: r.Form.Get("method"), Name: r.Form.Get("name")})
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc(metax.DeleteMethodPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.GeneralResponse{Code: 200})
+	})
+	mux.HandleFunc(metax.DeleteContractPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.GeneralResponse{Code: 200})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+		{ContractAddress: uniswapDapp, Method: "swap", ID: "api-swap"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp := common.HexToAddress(offlineDapp)
+
+	_, err = b.AddMethod(&metax.AddMethodRequest{ApiType: "native", MethodType: "write", Name: "approve", ContractAddress: offlineDapp, Method: "approve"})
+	assert.Nil(t, err)
+	info, err := b.MethodInfo(context.Background(), dapp, "approve")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-approve", info.ID)
+	assert.Equal(t, 3, len(b.MetaAPIs()))
+
+	_, err = b.DeleteMethod(&metax.DeleteMethodRequest{ContractAddress: offlineDapp, Method: "approve"})
+	assert.Nil(t, err)
+	_, err = b.MethodInfo(context.Background(), dapp, "approve")
+	assert.ErrorIs(t, err, metax.ErrApiIdNotFound)
+
+	_, err = b.DeleteContract(&metax.DeleteContractRequest{ContractAddress: offlineDapp, ContractType: "SC"})
+	assert.Nil(t, err)
+	_, err = b.MethodInfo(context.Background(), dapp, "transfer")
+	assert.ErrorIs(t, err, metax.ErrApiIdNotFound)
+	_, err = b.MethodInfo(context.Background(), common.HexToAddress(uniswapDapp), "swap")
+	assert.Nil(t, err)
+}
+
+func TestAPIIDLoadFailures(t *testing.T) {
+	metaAPI := &fakeMetaAPI{delay: 200 * time.Millisecond}
+	metaAPI.set(metax.MetaAPIInfo{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"})
+	mux := http.NewServeMux()
+	mux.Handle(metax.MetaAPIPath, metaAPI)
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp := common.HexToAddress(offlineDapp)
+
+	// the caller starting the load gives up, the one waiting still gets it
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		_, err := b.MethodInfo(ctx, dapp, "transfer")
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	info, err := b.MethodInfo(context.Background(), dapp, "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-transfer", info.ID)
+	assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&metaAPI.calls))
+
+	// a failed load is not retried by every call
+	fresh, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	metaAPI.mu.Lock()
+	metaAPI.fail = true
+	metaAPI.mu.Unlock()
+	_, err = fresh.MethodInfo(context.Background(), dapp, "transfer")
+	assert.NotNil(t, err)
+	start := time.Now()
+	_, err = fresh.MethodInfo(context.Background(), dapp, "transfer")
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&metaAPI.calls))
+}
@@ -1,9 +1,31 @@
 package test
 
 import (
-	"github.com/oblzh/bcnmy-go/metax"
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
+	"github.com/oblzh/bcnmy-go/metax"
+)
+
+// DAPPs of the fake chain tests
+const (
+	offlineDapp = "0x56b71565f6e7f9de4c3217a6e5d4133bc7fc67eb"
+	uniswapDapp = "0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a"
 )
 
 func buildBcnmy() *metax.Bcnmy {
@@ -11,3 +33,230 @@ func buildBcnmy() *metax.Bcnmy {
 	b = b.WithAuthToken(os.Getenv("authToken"))
 	return b
 }
+
+// fakeEndpoints points every Biconomy host at a local test server.
+func fakeEndpoints(server *httptest.Server) metax.Endpoints {
+	return metax.Endpoints{
+		APIHost:         server.URL,
+		DataHost:        server.URL,
+		BackendHost:     server.URL,
+		GaslessMetaHost: server.URL,
+	}
+}
+
+// fakeChain is a simulated chain that also answers the Biconomy forwarder
+// views and serves receipts for transactions "mined" by a fake relayer.
+type fakeChain struct {
+	*backends.SimulatedBackend
+	forwarder    common.Address
+	forwarderABI abi.ABI
+
+	mu       sync.Mutex
+	nonces   map[string]*big.Int
+	txs      map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	// callHook, when set, answers forwarder calls other than getNonce
+	callHook func(method *abi.Method, args []interface{}) ([]byte, error)
+	// contractHook, when set, answers calls to other contracts, reporting
+	// false for the calls it does not handle
+	contractHook func(call ethereum.CallMsg) ([]byte, bool, error)
+	// estimateHook, when set, answers gas estimations
+	estimateHook func(call ethereum.CallMsg) (uint64, error)
+	// contracts are addresses reported to have code, besides the forwarder
+	contracts []common.Address
+}
+
+// relayerKey is an account funded on every fakeChain, for self relaying.
+var relayerKey, _ = crypto.GenerateKey()
+
+func newFakeChain(forwarderAddress common.Address) *fakeChain {
+	forwarderABI, _ := forwarder.ForwarderMetaData.GetAbi()
+	alloc := core.GenesisAlloc{
+		crypto.PubkeyToAddress(relayerKey.PublicKey): {Balance: new(big.Int).Lsh(big.NewInt(1), 100)},
+	}
+	return &fakeChain{
+		SimulatedBackend: backends.NewSimulatedBackend(alloc, 8000000),
+		forwarder:        forwarderAddress,
+		forwarderABI:     *forwarderABI,
+		nonces:           make(map[string]*big.Int),
+		txs:              make(map[common.Hash]*types.Transaction),
+		receipts:         make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (c *fakeChain) setNonce(from common.Address, batchId int64, nonce int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nonces[fmt.Sprintf("%s-%v", from.Hex(), batchId)] = big.NewInt(nonce)
+}
+
+// mine registers a successful receipt for a new transaction and returns its hash.
+func (c *fakeChain) mine(data []byte) common.Hash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    uint64(len(c.txs)),
+		To:       &c.forwarder,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Data:     data,
+	})
+	c.txs[tx.Hash()] = tx
+	c.receipts[tx.Hash()] = &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      tx.Hash(),
+		BlockNumber: big.NewInt(1),
+		GasUsed:     50000,
+	}
+	return tx.Hash()
+}
+
+// revert marks the receipt of a mined transaction as failed.
+func (c *fakeChain) revert(txHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[txHash].Status = types.ReceiptStatusFailed
+}
+
+func (c *fakeChain) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if call.To == nil || *call.To != c.forwarder || len(call.Data) < 4 {
+		if c.contractHook != nil {
+			if out, ok, err := c.contractHook(call); ok {
+				return out, err
+			}
+		}
+		return c.SimulatedBackend.CallContract(ctx, call, blockNumber)
+	}
+	method, err := c.forwarderABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "getNonce" {
+		c.mu.Lock()
+		nonce, ok := c.nonces[fmt.Sprintf("%s-%v", args[0].(common.Address).Hex(), args[1].(*big.Int))]
+		c.mu.Unlock()
+		if !ok {
+			nonce = big.NewInt(0)
+		}
+		return method.Outputs.Pack(nonce)
+	}
+	if c.callHook != nil {
+		return c.callHook(method, args)
+	}
+	return nil, fmt.Errorf("fakeChain: %s not implemented", method.Name)
+}
+
+func (c *fakeChain) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	if c.estimateHook != nil {
+		return c.estimateHook(call)
+	}
+	return c.SimulatedBackend.EstimateGas(ctx, call)
+}
+
+func (c *fakeChain) hasCode(contract common.Address) bool {
+	if contract == c.forwarder {
+		return true
+	}
+	for _, address := range c.contracts {
+		if contract == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeChain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if c.hasCode(contract) {
+		return []byte{0x1}, nil
+	}
+	return c.SimulatedBackend.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *fakeChain) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	if c.hasCode(contract) {
+		return []byte{0x1}, nil
+	}
+	return c.SimulatedBackend.PendingCodeAt(ctx, contract)
+}
+
+// reorgOut removes the receipt of a mined transaction, keeping it pending.
+func (c *fakeChain) reorgOut(txHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.receipts, txHash)
+}
+
+// drop forgets a transaction and its receipt.
+func (c *fakeChain) drop(txHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.receipts, txHash)
+	delete(c.txs, txHash)
+}
+
+// include mines a known transaction again, in the block at number.
+func (c *fakeChain) include(txHash common.Hash, number int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[txHash] = &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(number),
+		GasUsed:     50000,
+	}
+}
+
+// TransactionReceipt serves the fake receipts, in the simulated block at
+// their number once it was committed.
+func (c *fakeChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	receipt, ok := c.receipts[txHash]
+	if ok && receipt.BlockHash == (common.Hash{}) {
+		if header, err := c.SimulatedBackend.HeaderByNumber(ctx, receipt.BlockNumber); err == nil && header != nil {
+			receipt.BlockHash = header.Hash()
+		}
+	}
+	c.mu.Unlock()
+	if ok {
+		return receipt, nil
+	}
+	return c.SimulatedBackend.TransactionReceipt(ctx, txHash)
+}
+
+func (c *fakeChain) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	c.mu.Lock()
+	tx, ok := c.txs[txHash]
+	c.mu.Unlock()
+	if ok {
+		return tx, false, nil
+	}
+	return c.SimulatedBackend.TransactionByHash(ctx, txHash)
+}
+
+// buildFakeChainBcnmy builds a Bcnmy against a fake Biconomy and a fake
+// chain on Mumbai's chain ID, without touching the network.
+func buildFakeChainBcnmy(handler http.Handler, opts ...metax.Option) (*metax.Bcnmy, *fakeChain, *httptest.Server, error) {
+	server := httptest.NewServer(handler)
+	chain := newFakeChain(metax.ForwarderAddressMap["80001"])
+	opts = append([]metax.Option{
+		metax.WithContractBackend(chain),
+		metax.WithChainID(big.NewInt(80001)),
+		metax.WithEndpoints(fakeEndpoints(server)),
+		metax.WithHTTPTimeout(5 * time.Second),
+	}, opts...)
+	b, err := metax.New("test-api-key", opts...)
+	if err != nil {
+		server.Close()
+		return nil, nil, nil, err
+	}
+	return b, chain, server, nil
+}
+
+func buildOfflineBcnmy(handler http.Handler, opts ...metax.Option) (*metax.Bcnmy, *httptest.Server, error) {
+	b, _, server, err := buildFakeChainBcnmy(handler, opts...)
+	return b, server, err
+}
@@ -1,8 +1,11 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,3 +23,76 @@ func TestCheckLimits(t *testing.T) {
 	assert.Nil(t, err)
 	fmt.Println(resp)
 }
+
+func TestDappRegistry(t *testing.T) {
+	var chain *fakeChain
+	var mu sync.Mutex
+	relayed := make(map[string][]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		req, metaTxMessage, _ := decodeRelay(t, r)
+		assert.Equal(t, metaTxMessage.To.Hex(), req.To)
+		mu.Lock()
+		relayed[req.ApiID] = append(relayed[req.ApiID], req.To)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true, Message: r.URL.Query().Get("apiId")})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+		{ContractAddress: uniswapDapp, Method: "isTrustedForwarder", ID: "api-uniswap"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	transfer, err := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	uniswap, err := b.RegisterDapp(demo.UniswapDemoABI, common.HexToAddress(uniswapDapp))
+	assert.Nil(t, err)
+	_, err = b.RegisterDapp("not json", common.HexToAddress(uniswapDapp))
+	assert.NotNil(t, err)
+	found, ok := b.Dapp(common.HexToAddress(uniswapDapp))
+	assert.True(t, ok)
+	assert.Equal(t, uniswap, found)
+	assert.Equal(t, 2, len(b.Dapps()))
+
+	// the client level methods have no dapp until WithDapp
+	_, err = b.CheckLimits("0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "transfer")
+	assert.ErrorIs(t, err, metax.ErrApiIdNotFound)
+	_, err = uniswap.Pack("transfer", transferParams()...)
+	assert.NotNil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := transfer.RawTransact(signer, "transfer", transferParams()...)
+			assert.Nil(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, _, err := uniswap.RawTransact(signer, "isTrustedForwarder", common.HexToAddress(offlineDapp))
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4, len(relayed["api-transfer"]))
+	assert.Equal(t, 4, len(relayed["api-uniswap"]))
+	for _, to := range relayed["api-uniswap"] {
+		assert.Equal(t, common.HexToAddress(uniswapDapp).Hex(), to)
+	}
+
+	resp, err := uniswap.CheckLimits("0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "isTrustedForwarder")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-uniswap", resp.Message)
+
+	_, err = b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	resp, err = b.CheckLimits("0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-transfer", resp.Message)
+}
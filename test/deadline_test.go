@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestDeadlinePolicy(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	relays := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		relays++
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	policy := metax.DeadlinePolicy{TTL: 10 * time.Minute, ClockSkew: 30 * time.Second, MaxTTL: time.Hour}
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithDeadlinePolicy(policy), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	at := func(d time.Duration) *big.Int { return big.NewInt(time.Now().Add(d).Unix()) }
+	assert.Nil(t, b.ValidateDeadline(at(2*time.Minute)))
+	assert.ErrorIs(t, b.ValidateDeadline(at(10*time.Second)), metax.ErrDeadlineExpired)
+	assert.ErrorIs(t, b.ValidateDeadline(at(-time.Minute)), metax.ErrDeadlineExpired)
+	assert.ErrorIs(t, b.ValidateDeadline(at(3*time.Hour)), metax.ErrDeadlineInvalid)
+	assert.ErrorIs(t, b.ValidateDeadline(big.NewInt(time.Now().Add(time.Hour).UnixMilli())), metax.ErrDeadlineInvalid)
+
+	// signed with the policy TTL, or the per call deadline
+	sub, err := dapp.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Now().Add(10*time.Minute).Unix(), sub.Message.Deadline.Int64(), 5)
+	sub, err = dapp.SubmitWithOpts(context.Background(), &metax.TransactOpts{Deadline: time.Now().Add(20 * time.Minute)}, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Now().Add(20*time.Minute).Unix(), sub.Message.Deadline.Int64(), 5)
+	_, err = dapp.SubmitWithOpts(context.Background(), &metax.TransactOpts{Deadline: time.Now().Add(-time.Second)}, signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrDeadlineExpired)
+	assert.Equal(t, 2, relays)
+
+	// frontend requests in milliseconds are refused before relaying
+	message := sub.Message
+	message.Deadline = big.NewInt(time.Now().Add(time.Hour).UnixMilli())
+	_, err = dapp.SubmitEnhanced(signer.GetAddress().Hex(), "transfer", make([]byte, 65), message, "")
+	assert.ErrorIs(t, err, metax.ErrDeadlineInvalid)
+	assert.Equal(t, 2, relays)
+
+	_, _, _, err = buildFakeChainBcnmy(mux, metax.WithDeadlinePolicy(metax.DeadlinePolicy{TTL: time.Minute, ClockSkew: time.Minute}))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestEndpointsProfile(t *testing.T) {
+	endpoints, err := metax.LookupEndpoints(metax.ProductionProfile)
+	assert.Nil(t, err)
+	assert.Equal(t, metax.MetaAPIURL, endpoints.APIURL(metax.MetaAPIPath))
+	assert.Equal(t, metax.UserLimitURL, endpoints.DataURL(metax.UserLimitPath))
+	assert.Equal(t, metax.BackendDappURL, endpoints.BackendURL(metax.BackendDappPath))
+	assert.Equal(t, metax.MetaTransactionStatusURL, endpoints.GaslessMetaURL(metax.MetaTransactionStatusPath))
+
+	_, err = metax.LookupEndpoints("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestEndpointsCustom(t *testing.T) {
+	metax.EndpointProfiles["local"] = metax.Endpoints{
+		APIHost:         "http://127.0.0.1:8080/",
+		DataHost:        "http://127.0.0.1:8081",
+		BackendHost:     "http://127.0.0.1:8082",
+		GaslessMetaHost: "http://127.0.0.1:8083",
+	}
+	defer delete(metax.EndpointProfiles, "local")
+
+	endpoints, err := metax.LookupEndpoints("local")
+	assert.Nil(t, err)
+	assert.Nil(t, endpoints.Validate())
+	assert.Equal(t, "http://127.0.0.1:8080/api/v2/meta-tx/native", endpoints.APIURL(metax.MetaTxNativePath))
+
+	endpoints.DataHost = "127.0.0.1:8081"
+	assert.NotNil(t, endpoints.Validate())
+}
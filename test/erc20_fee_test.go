@@ -0,0 +1,182 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	"github.com/oblzh/bcnmy-go/abi/erc20forwarder"
+	"github.com/oblzh/bcnmy-go/abi/token"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+var (
+	feeProxy   = common.HexToAddress("0x00000000000000000000000000000000000FEE01")
+	feeManager = common.HexToAddress("0x00000000000000000000000000000000000FEE02")
+	feeOracle  = common.HexToAddress("0x00000000000000000000000000000000000FEE03")
+	feeToken   = common.HexToAddress("0x00000000000000000000000000000000000FEE04")
+)
+
+// fakeFeeContracts answers the fee proxy, fee manager, oracle and fee token
+// views: 10% fee premium, 1 token = 0.5 native with 18 oracle decimals.
+type fakeFeeContracts struct {
+	balance   *big.Int
+	allowance *big.Int
+}
+
+func (f *fakeFeeContracts) hook(call ethereum.CallMsg) ([]byte, bool, error) {
+	var metaData *bind.MetaData
+	switch *call.To {
+	case feeProxy:
+		metaData = erc20forwarder.ERC20FeeProxyMetaData
+	case feeManager:
+		metaData = erc20forwarder.FeeManagerMetaData
+	case feeOracle:
+		metaData = erc20forwarder.OracleAggregatorMetaData
+	case feeToken:
+		metaData = token.ERC20MetaData
+	default:
+		return nil, false, nil
+	}
+	parsed, _ := metaData.GetAbi()
+	method, err := parsed.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, true, err
+	}
+	out, err := f.answer(method)
+	return out, true, err
+}
+
+func (f *fakeFeeContracts) answer(method *abi.Method) ([]byte, error) {
+	switch method.Name {
+	case "feeManager":
+		return method.Outputs.Pack(feeManager)
+	case "oracleAggregator":
+		return method.Outputs.Pack(feeOracle)
+	case "baseGas":
+		return method.Outputs.Pack(big.NewInt(30000))
+	case "transferHandlerGas":
+		return method.Outputs.Pack(big.NewInt(40000))
+	case "getTokenAllowed":
+		return method.Outputs.Pack(true)
+	case "getFeeMultiplier":
+		return method.Outputs.Pack(uint16(11000))
+	case "getTokenPrice":
+		return method.Outputs.Pack(big.NewInt(5e17))
+	case "getTokenOracleDecimals":
+		return method.Outputs.Pack(uint8(18))
+	case "allowance":
+		return method.Outputs.Pack(f.allowance)
+	case "balanceOf":
+		return method.Outputs.Pack(f.balance)
+	}
+	return method.Outputs.Pack()
+}
+
+func TestERC20Fee(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	var relayed *metax.MetaTxMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		_, relayed, _ = decodeRelay(t, r)
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	// the simulated backend signs for chain 1337
+	b, chain, server, err := buildFakeChainBcnmy(mux,
+		metax.WithChainID(big.NewInt(1337)),
+		metax.WithForwarderAddress(metax.ForwarderAddressMap["80001"]),
+		metax.WithERC20FeeProxy(feeProxy),
+		metax.WithSelfRelay(metax.SelfRelayConfig{Signer: metax.NewSignerFromKey(relayerKey)}),
+		metax.WithAPIIDs([]metax.MetaAPIInfo{{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"}}),
+	)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	funds := &fakeFeeContracts{balance: big.NewInt(0), allowance: big.NewInt(0)}
+	chain.contractHook = funds.hook
+	chain.contracts = []common.Address{feeProxy}
+
+	gasPrice, err := chain.SuggestGasPrice(context.Background())
+	assert.Nil(t, err)
+	quote, err := b.QuoteFee(signer.GetAddress(), feeToken, 100000)
+	assert.Nil(t, err)
+	tokenGasPrice := new(big.Int).Mul(gasPrice, big.NewInt(2))
+	assert.Equal(t, tokenGasPrice, quote.TokenGasPrice)
+	// (100000 + 30000 + 40000) * tokenGasPrice * 1.1
+	assert.Equal(t, new(big.Int).Mul(tokenGasPrice, big.NewInt(187000)), quote.Fee)
+
+	opts := &metax.TransactOpts{FeeToken: feeToken, TokenSpend: big.NewInt(1000)}
+	_, err = b.SubmitWithOpts(context.Background(), opts, signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrFeeAllowance)
+
+	funds.allowance = new(big.Int).Lsh(big.NewInt(1), 128)
+	funds.balance = big.NewInt(1000)
+	_, err = b.SubmitWithOpts(context.Background(), opts, signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrFeeBalance)
+	var fundsErr *metax.FeeFundsError
+	assert.ErrorAs(t, err, &fundsErr)
+	assert.True(t, fundsErr.Need.Cmp(fundsErr.Have) > 0)
+
+	// the fee proxy executes token paid requests, Biconomy would not charge them
+	funds.balance = new(big.Int).Lsh(big.NewInt(1), 128)
+	sub, err := b.SubmitWithOpts(context.Background(), opts, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Nil(t, relayed)
+	assert.Equal(t, metax.RelayerSelf, sub.Relayer)
+	tx, _, err := chain.TransactionByHash(context.Background(), sub.TxHash)
+	assert.Nil(t, err)
+	assert.Equal(t, feeProxy, *tx.To())
+	proxyABI, _ := erc20forwarder.ERC20FeeProxyMetaData.GetAbi()
+	method, err := proxyABI.MethodById(tx.Data()[:4])
+	assert.Nil(t, err)
+	assert.Equal(t, "executeEIP712", method.Name)
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	assert.Nil(t, err)
+	req := *abi.ConvertType(args[0], new(erc20forwarder.ERC20ForwardRequestTypesERC20ForwardRequest)).(*erc20forwarder.ERC20ForwardRequestTypesERC20ForwardRequest)
+	assert.Equal(t, feeToken, req.Token)
+	assert.Equal(t, sub.Fee.TokenGasPrice, req.TokenGasPrice)
+	assert.Equal(t, sub.Fee.TxGas, req.TxGas.Uint64())
+
+	// without a fee token the dapp pays through Biconomy as before
+	_, err = b.SubmitWithOpts(context.Background(), nil, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, common.Address{}, relayed.Token)
+	assert.Equal(t, "0", relayed.TokenGasPrice)
+}
+
+func TestERC20FeeNeedsSelfRelay(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	relayed := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		relayed++
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux,
+		metax.WithERC20FeeProxy(feeProxy),
+		metax.WithAPIIDs([]metax.MetaAPIInfo{{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"}}),
+	)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	chain.contractHook = (&fakeFeeContracts{balance: big.NewInt(1e18), allowance: big.NewInt(1e18)}).hook
+
+	_, err = b.SubmitWithOpts(context.Background(), &metax.TransactOpts{FeeToken: feeToken}, signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrFeeRelayUnavailable)
+	assert.Equal(t, 0, relayed)
+
+	// a client connecting lazily does not panic
+	lazy, err := metax.New("test-api-key", metax.WithRPC("http://127.0.0.1:1"))
+	assert.Nil(t, err)
+	err = lazy.CheckFeeFunds(context.Background(), &metax.FeeQuote{Token: feeToken, FeeProxy: feeProxy, Fee: big.NewInt(1)}, signer.GetAddress(), nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	"github.com/oblzh/bcnmy-go/abi/recipient"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestERC2771Recipient(t *testing.T) {
+	var chain *fakeChain
+	relays := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		relays++
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithTrustedForwarderCheck(true), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+		{ContractAddress: uniswapDapp, Method: "transfer", ID: "api-uniswap"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	parsed, _ := recipient.RecipientMetaData.GetAbi()
+	forwarderAddress := metax.ForwarderAddressMap["80001"]
+	checks := 0
+	chain.contractHook = func(call ethereum.CallMsg) ([]byte, bool, error) {
+		if *call.To != common.HexToAddress(offlineDapp) && *call.To != common.HexToAddress(uniswapDapp) {
+			return nil, false, nil
+		}
+		method, err := parsed.MethodById(call.Data[:4])
+		if err != nil {
+			return nil, true, err
+		}
+		if method.Name == "versionRecipient" {
+			out, err := method.Outputs.Pack("1")
+			return out, true, err
+		}
+		checks++
+		args, _ := method.Inputs.Unpack(call.Data[4:])
+		trusted := args[0].(common.Address) == forwarderAddress && *call.To == common.HexToAddress(offlineDapp)
+		out, err := method.Outputs.Pack(trusted)
+		return out, true, err
+	}
+
+	trusted, err := b.IsTrustedForwarder(context.Background(), common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	assert.True(t, trusted)
+	version, err := b.VersionRecipient(context.Background(), common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", version)
+
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	transfer, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	for i := 0; i < 2; i++ {
+		_, _, _, err = transfer.RawTransact(signer, "transfer", transferParams()...)
+		assert.Nil(t, err)
+	}
+	// the positive answer is remembered
+	assert.Equal(t, 2, checks)
+
+	untrusted, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(uniswapDapp))
+	_, err = untrusted.Submit(signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrForwarderNotTrusted)
+	noCode, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"))
+	err = noCode.CheckTrustedForwarder(context.Background())
+	var notTrusted *metax.ForwarderNotTrustedError
+	assert.ErrorAs(t, err, &notTrusted)
+	assert.NotNil(t, notTrusted.Err)
+	assert.Equal(t, 2, relays)
+
+	// the check is off by default
+	unchecked, _, uncheckedServer, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: uniswapDapp, Method: "transfer", ID: "api-uniswap"},
+	}))
+	assert.Nil(t, err)
+	defer uncheckedServer.Close()
+	untrusted, _ = unchecked.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(uniswapDapp))
+	_, err = untrusted.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, relays)
+
+	// lazy clients connect before asking the recipient
+	lazy, err := metax.New("test-api-key", metax.WithRPC("http://127.0.0.1:1"))
+	assert.Nil(t, err)
+	_, err = lazy.VersionRecipient(context.Background(), common.HexToAddress(offlineDapp))
+	assert.NotNil(t, err)
+
+	data, _ := transfer.Pack("transfer", transferParams()...)
+	call := transfer.ForwardCall(signer.GetAddress(), data)
+	assert.Equal(t, forwarderAddress, call.From)
+	original, sender, err := metax.SplitSender(call.Data)
+	assert.Nil(t, err)
+	assert.Equal(t, data, original)
+	assert.Equal(t, signer.GetAddress(), sender)
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestMetaTxResponseErr(t *testing.T) {
+	resp := &metax.MetaTxResponse{Code: metax.UserLimitExhaustedCode, Message: "User limit exhausted"}
+	err := resp.Err()
+	assert.True(t, errors.Is(err, metax.ErrLimitExhausted))
+	assert.True(t, errors.Is(err, metax.ErrUserLimitExhausted))
+	assert.False(t, errors.Is(err, metax.ErrDappLimitExhausted))
+	assert.True(t, errors.Is(err, metax.ErrRelayerRejected))
+
+	var limitErr *metax.LimitError
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, resp, limitErr.MetaTxResponse)
+
+	resp = &metax.MetaTxResponse{Code: 417, Message: "Expectation failed"}
+	err = resp.Err()
+	assert.True(t, errors.Is(err, metax.ErrRelayerRejected))
+	assert.False(t, errors.Is(err, metax.ErrLimitExhausted))
+}
+
+func TestSendMetaNativeTxLimitError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":    metax.DappLimitExhaustedCode,
+			"message": "DApp limit exhausted",
+		})
+	})
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+
+	resp, err := b.SendMetaNativeTxContext(context.Background(), &metax.MetaTxRequest{})
+	assert.NotNil(t, resp)
+	assert.True(t, errors.Is(err, metax.ErrDappLimitExhausted))
+}
+
+func TestBackendUnauthorizedRelogin(t *testing.T) {
+	loggedIn := false
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.BackendLoginPath, func(w http.ResponseWriter, r *http.Request) {
+		loggedIn = true
+		json.NewEncoder(w).Encode(metax.LoginResponse{Message: "ok"})
+	})
+	mux.HandleFunc(metax.BackendDappPath, func(w http.ResponseWriter, r *http.Request) {
+		if !loggedIn {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(metax.DappResponse{Code: 200})
+	})
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	assert.Nil(t, b.WithBackend("user@example.com", "passwd", 5*time.Second))
+
+	_, err = b.BackendDappListContext(context.Background())
+	assert.True(t, errors.Is(err, metax.ErrUnauthorized))
+
+	loggedIn = false
+	resp, err := b.GetBackendDappsContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.Code)
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestBuildAndSubmitSigned(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	other, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/1")
+	var chain *fakeChain
+	var relayed []metax.MetaTxRequest
+	reject := false
+	b, chain, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		var req metax.MetaTxRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if reject {
+			json.NewEncoder(w).Encode(metax.MetaTxResponse{Code: 417, Message: "rejected"})
+			return
+		}
+		relayed = append(relayed, req)
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	defer stop()
+	chain.setNonce(signer.GetAddress(), 0, 4)
+
+	payload, err := b.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, "transfer(address,address,uint256)", payload.Method)
+	assert.Equal(t, int64(4), payload.Message.BatchNonce.Int64())
+	assert.NotZero(t, payload.Message.TxGas)
+
+	// the frontend gets JSON and signs its typedData with eth_signTypedData_v4
+	encoded, err := json.Marshal(payload)
+	assert.Nil(t, err)
+	var fields map[string]json.RawMessage
+	json.Unmarshal(encoded, &fields)
+	var typedData apitypes.TypedData
+	assert.Nil(t, json.Unmarshal(fields["typedData"], &typedData))
+	assert.Equal(t, metax.ForwardRequestType, typedData.PrimaryType)
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	assert.Nil(t, err)
+	assert.Equal(t, payload.Hash, common.BytesToHash(digest))
+
+	var received metax.ForwardRequestPayload
+	assert.Nil(t, json.Unmarshal(encoded, &received))
+	wrong, _ := other.SignTypedData(typedData)
+	_, err = b.SubmitSigned(&received, wrong)
+	assert.ErrorIs(t, err, metax.ErrSignatureInvalid)
+	assert.Empty(t, relayed)
+
+	signature, err := signer.SignTypedData(typedData)
+	assert.Nil(t, err)
+	sub, err := b.SubmitSigned(&received, signature)
+	assert.Nil(t, err)
+	assert.Len(t, relayed, 1)
+	assert.Equal(t, "api-transfer", relayed[0].ApiID)
+	_, _, err = sub.Wait(context.Background(), 1)
+	assert.Nil(t, err)
+	chain.setNonce(signer.GetAddress(), 0, 5)
+
+	// a tampered message does not match the signature, its nonce stays held
+	tampered, _ := b.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Equal(t, int64(5), tampered.Message.BatchNonce.Int64())
+	signature, _ = signer.SignTypedData(tampered.TypedData)
+	tampered.Message.TxGas++
+	_, err = b.SubmitSigned(tampered, signature)
+	assert.ErrorIs(t, err, metax.ErrSignatureInvalid)
+	tampered.Message.TxGas--
+	_, err = b.SubmitSigned(tampered, signature)
+	assert.Nil(t, err)
+	assert.Len(t, relayed, 2)
+	chain.setNonce(signer.GetAddress(), 0, 6)
+
+	// concurrent builds hold distinct nonces, each claimed by its signature
+	payloads := make([]*metax.ForwardRequestPayload, 2)
+	var wg sync.WaitGroup
+	for i := range payloads {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payloads[i], _ = b.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+		}(i)
+	}
+	wg.Wait()
+	assert.ElementsMatch(t, []int64{6, 7}, []int64{payloads[0].Message.BatchNonce.Int64(), payloads[1].Message.BatchNonce.Int64()})
+	for _, payload := range payloads {
+		signature, _ = signer.SignTypedData(payload.TypedData)
+		_, err = b.SubmitSigned(payload, signature)
+		assert.Nil(t, err)
+	}
+	assert.Len(t, relayed, 4)
+	chain.setNonce(signer.GetAddress(), 0, 8)
+
+	// a payload that is not relayed leaves its nonce to the next one
+	next, err := b.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), next.Message.BatchNonce.Int64())
+	reject = true
+	signature, _ = signer.SignTypedData(next.TypedData)
+	_, err = b.SubmitSigned(next, signature)
+	assert.ErrorIs(t, err, metax.ErrRelayerRejected)
+	reject = false
+	again, err := b.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), again.Message.BatchNonce.Int64())
+	signature, _ = signer.SignTypedData(again.TypedData)
+	_, err = b.SubmitSigned(again, signature)
+	assert.Nil(t, err)
+}
+
+func TestBuildNonceHold(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithNonceHold(50*time.Millisecond), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	// once the hold expires the nonce goes to the next payload, the first
+	// one signed claims it
+	first, err := dapp.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	var second *metax.ForwardRequestPayload
+	assert.Eventually(t, func() bool {
+		second, err = dapp.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+		return err == nil && second.Message.BatchNonce.Cmp(first.Message.BatchNonce) == 0
+	}, time.Second, 20*time.Millisecond)
+	signature, _ := signer.SignTypedData(second.TypedData)
+	_, err = b.SubmitSigned(second, signature)
+	assert.Nil(t, err)
+	signature, _ = signer.SignTypedData(first.TypedData)
+	_, err = b.SubmitSigned(first, signature)
+	assert.ErrorIs(t, err, metax.ErrNonceUnavailable)
+
+	// without a hold concurrent builds share the nonce
+	_, err = metax.New("test-api-key", metax.WithNonceHold(-time.Second))
+	assert.NotNil(t, err)
+	unheld, unheldChain, unheldServer, err := buildFakeChainBcnmy(mux, metax.WithNonceHold(0), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer unheldServer.Close()
+	unheldChain.setNonce(signer.GetAddress(), 0, 3)
+	unheldDapp, _ := unheld.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	first, err = unheldDapp.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	second, err = unheldDapp.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), first.Message.BatchNonce.Int64())
+	assert.Equal(t, int64(3), second.Message.BatchNonce.Int64())
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestGasStrategy(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	strategy := metax.GasStrategy{
+		ForwarderPath: true,
+		Multiplier:    1.5,
+		Buffer:        1000,
+		Max:           100000,
+		Methods:       map[string]uint64{"transferOwnership( address )": 70000},
+		Fallback:      80000,
+	}
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithGasStrategy(strategy), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	var estimate uint64 = 60000
+	var estimateErr error
+	var calls []ethereum.CallMsg
+	chain.estimateHook = func(call ethereum.CallMsg) (uint64, error) {
+		calls = append(calls, call)
+		return estimate, estimateErr
+	}
+
+	// estimated from the forwarder with the signer appended, then buffered
+	gas, err := dapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(91000), gas)
+	assert.Equal(t, metax.ForwarderAddressMap["80001"], calls[0].From)
+	data, sender, err := metax.SplitSender(calls[0].Data)
+	assert.Nil(t, err)
+	assert.Equal(t, signer.GetAddress(), sender)
+	transfer, _ := dapp.Method("transfer")
+	assert.Equal(t, transfer.ID, data[:4])
+
+	sub, err := dapp.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(91000), sub.Message.TxGas)
+
+	// capped
+	estimate = 90000
+	gas, _ = dapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transfer", transferParams()...)
+	assert.Equal(t, uint64(100000), gas)
+
+	// static per method, not estimated
+	calls = nil
+	gas, err = dapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transferOwnership", signer.GetAddress())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(70000), gas)
+	assert.Empty(t, calls)
+
+	// a revert falls back, other failures are returned
+	estimateErr = errors.New("execution reverted")
+	gas, err = dapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(80000), gas)
+	estimateErr = errors.New("connection refused")
+	_, err = dapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transfer", transferParams()...)
+	assert.NotNil(t, err)
+
+	// per call override, the raw estimate from the signer
+	estimate, estimateErr, calls = 50000, nil, nil
+	gas, err = dapp.EstimateTxGas(context.Background(), &metax.TransactOpts{GasStrategy: &metax.GasStrategy{}}, signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(50000), gas)
+	assert.Equal(t, signer.GetAddress(), calls[0].From)
+
+	// the signature is more specific than the name
+	calls = nil
+	specific := &metax.GasStrategy{Methods: map[string]uint64{"transfer": 1000, transfer.Sig: 2000, hexutil.Encode(transfer.ID): 3000}}
+	for i := 0; i < 8; i++ {
+		gas, err = dapp.EstimateTxGas(context.Background(), &metax.TransactOpts{GasStrategy: specific}, signer.GetAddress(), "transfer", transferParams()...)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2000), gas)
+	}
+	assert.Empty(t, calls)
+
+	_, _, _, err = buildFakeChainBcnmy(mux, metax.WithGasStrategy(metax.GasStrategy{Multiplier: -1}))
+	assert.NotNil(t, err)
+	_, _, _, err = buildFakeChainBcnmy(mux, metax.WithGasStrategy(metax.GasStrategy{Methods: map[string]uint64{"0xA9059CBB": 1, "0xa9059cbb": 2}}))
+	assert.NotNil(t, err)
+
+	// the forwarder strategy by default
+	defaulted, defaultChain, defaultServer, err := buildFakeChainBcnmy(mux)
+	assert.Nil(t, err)
+	defer defaultServer.Close()
+	defaultChain.estimateHook = func(call ethereum.CallMsg) (uint64, error) {
+		return 50000, nil
+	}
+	defaultDapp, _ := defaulted.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	gas, err = defaultDapp.EstimateTxGas(context.Background(), nil, signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(70000), gas)
+}
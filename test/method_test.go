@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+const overloadedABI = `[
+	{"type":"function","name":"safeTransferFrom","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}]},
+	{"type":"function","name":"safeTransferFrom","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}]},
+	{"type":"function","name":"mint","stateMutability":"nonpayable","outputs":[],"inputs":[{"name":"amount","type":"uint256"}]},
+	{"type":"function","name":"mint","stateMutability":"nonpayable","outputs":[],"inputs":[{"name":"amount","type":"int256"}]}
+]`
+
+func TestMethodOverloads(t *testing.T) {
+	var chain *fakeChain
+	relayed := make(map[string]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		req, metaTxMessage, _ := decodeRelay(t, r)
+		relayed[req.ApiID] = metaTxMessage.Data[:10]
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "safeTransferFrom(address, address, uint256)", ID: "api-safe"},
+		{ContractAddress: offlineDapp, Method: "0xb88d4fde", ID: "api-safe-data"},
+		{ContractAddress: offlineDapp, Method: "mint", ID: "api-mint"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	dapp, err := b.RegisterDapp(overloadedABI, common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+
+	from := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	to := common.HexToAddress(uniswapDapp)
+	m, err := dapp.Method("safeTransferFrom", from, to, big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "safeTransferFrom(address,address,uint256)", m.Sig)
+	m, err = dapp.Method("safeTransferFrom", from, to, big.NewInt(1), []byte{0x1})
+	assert.Nil(t, err)
+	assert.Equal(t, "0xb88d4fde", hexutil.Encode(m.ID))
+	m, err = dapp.Method("0x42842e0e")
+	assert.Nil(t, err)
+	assert.Equal(t, "safeTransferFrom(address,address,uint256)", m.Sig)
+	_, err = dapp.Method("safeTransferFrom", from, to)
+	assert.ErrorIs(t, err, metax.ErrMethodNotFound)
+	_, err = dapp.Method("mint", big.NewInt(1))
+	assert.ErrorIs(t, err, metax.ErrAmbiguousMethod)
+	m, err = dapp.Method("mint(int256)", big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "mint(int256)", m.Sig)
+
+	// each overload is relayed with the apiId registered for it
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	_, _, _, err = dapp.RawTransact(signer, "safeTransferFrom", from, to, big.NewInt(1))
+	assert.Nil(t, err)
+	_, _, _, err = dapp.RawTransact(signer, "safeTransferFrom", from, to, big.NewInt(1), []byte{0x1})
+	assert.Nil(t, err)
+	_, _, _, err = dapp.RawTransact(signer, "mint(uint256)", big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "0x42842e0e", relayed["api-safe"])
+	assert.Equal(t, "0xb88d4fde", relayed["api-safe-data"])
+	assert.Equal(t, 3, len(relayed))
+
+	// frontend data must call the named method of the ABI
+	data, _ := dapp.Pack("mint(uint256)", big.NewInt(1))
+	for _, calldata := range []string{hexutil.Encode(data), "0xdeadbeef", "0x12", "not hex"} {
+		message := &metax.MetaTxMessage{From: signer.GetAddress(), To: dapp.Address(), Data: calldata}
+		_, err = dapp.SubmitEnhancedWithOpts(context.Background(), nil, signer.GetAddress().Hex(), "safeTransferFrom", make([]byte, 65), message, "")
+		assert.ErrorIs(t, err, metax.ErrMethodMismatch, calldata)
+	}
+	assert.Equal(t, 3, len(relayed))
+
+	info, err := dapp.MethodInfo(context.Background(), "0x42842e0e")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-safe", info.ID)
+	info, err = dapp.MethodInfo(context.Background(), "safeTransferFrom(address,address,uint256,bytes)")
+	assert.Nil(t, err)
+	assert.Equal(t, "api-safe-data", info.ID)
+}
@@ -0,0 +1,182 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+// noncesRelay is a fake relay recording the (batchId, batchNonce) of every
+// request, rejecting them while reject is set and dropping the connection
+// without an answer while drop is set.
+type noncesRelay struct {
+	t     *testing.T
+	chain *fakeChain
+
+	mu     sync.Mutex
+	seen   [][2]int64
+	reject bool
+	drop   bool
+}
+
+func (r *noncesRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	_, metaTxMessage, _ := decodeRelay(r.t, req)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = append(r.seen, [2]int64{metaTxMessage.BatchId.Int64(), metaTxMessage.BatchNonce.Int64()})
+	if r.reject {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{Code: 417, Message: "Not allowed"})
+		return
+	}
+	if r.drop {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: r.chain.mine(nil)})
+}
+
+func (r *noncesRelay) last() [2]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seen[len(r.seen)-1]
+}
+
+func buildNoncesBcnmy(t *testing.T, opts ...metax.Option) (*metax.Bcnmy, *fakeChain, *noncesRelay, func()) {
+	relay := &noncesRelay{t: t}
+	mux := http.NewServeMux()
+	mux.Handle(metax.MetaTxNativePath, relay)
+	opts = append(opts, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	b, chain, server, err := buildFakeChainBcnmy(mux, opts...)
+	assert.Nil(t, err)
+	relay.chain = chain
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	return b, chain, relay, server.Close
+}
+
+func TestSequentialNonces(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, chain, relay, stop := buildNoncesBcnmy(t)
+	defer stop()
+	chain.setNonce(signer.GetAddress(), 0, 7)
+
+	first, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 7}, relay.last())
+	second, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 8}, relay.last())
+
+	// a rejected request gives its nonce back
+	relay.reject = true
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrRelayerRejected)
+	assert.Equal(t, [2]int64{0, 9}, relay.last())
+	relay.reject = false
+	third, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 9}, relay.last())
+
+	// while requests are in flight the local sequence wins over the chain
+	chain.setNonce(signer.GetAddress(), 0, 8)
+	fourth, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 10}, relay.last())
+
+	// once everything is mined the chain is authoritative again
+	_, _, err = first.Wait(context.Background(), 1)
+	assert.Nil(t, err)
+	for _, sub := range []*metax.Submission{second, third, fourth} {
+		_, _, err = sub.Wait(context.Background(), 1)
+		assert.Nil(t, err)
+	}
+	chain.setNonce(signer.GetAddress(), 0, 20)
+	_, _, _, err = b.RawTransact(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 20}, relay.last())
+}
+
+func TestParallelNonces(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, chain, relay, stop := buildNoncesBcnmy(t, metax.WithNonceOrdering(metax.ParallelNonces, 3))
+	defer stop()
+	chain.setNonce(signer.GetAddress(), 1, 4)
+
+	var subs []*metax.Submission
+	for _, expected := range [][2]int64{{0, 0}, {1, 4}, {2, 0}, {0, 1}} {
+		sub, err := b.Submit(signer, "transfer", transferParams()...)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, relay.last())
+		subs = append(subs, sub)
+	}
+
+	// batch 1 is free again after its request is mined
+	_, _, err := subs[1].Wait(context.Background(), 1)
+	assert.Nil(t, err)
+	chain.setNonce(signer.GetAddress(), 1, 5)
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{1, 5}, relay.last())
+
+	// other signers have their own batches
+	other, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/1")
+	reservation, err := b.ReserveNonce(context.Background(), other.GetAddress())
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(0), reservation.BatchId)
+	assert.Equal(t, big.NewInt(0), reservation.BatchNonce)
+	reservation.Fail()
+
+	_, err = metax.New("key", metax.WithRPC("http://localhost"), metax.WithNonceOrdering(metax.ParallelNonces, 1))
+	assert.NotNil(t, err)
+}
+
+func TestNonceRelayOutcomeUnknown(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, chain, relay, stop := buildNoncesBcnmy(t)
+	defer stop()
+	chain.setNonce(signer.GetAddress(), 0, 5)
+
+	_, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 5}, relay.last())
+
+	// the relay may have broadcast a request it did not answer
+	relay.drop = true
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.NotNil(t, err)
+	assert.Equal(t, [2]int64{0, 6}, relay.last())
+	relay.drop = false
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 7}, relay.last())
+}
+
+func TestNonceReservationExpires(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, chain, relay, stop := buildNoncesBcnmy(t)
+	defer stop()
+	chain.setNonce(signer.GetAddress(), 0, 3)
+
+	// nobody waits for the request, it is released at its deadline
+	deadline := time.Unix(time.Now().Add(2*time.Second).Unix(), 0)
+	_, err := b.SubmitWithOpts(context.Background(), &metax.TransactOpts{Deadline: deadline}, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 3}, relay.last())
+	time.Sleep(time.Until(deadline) + 200*time.Millisecond)
+
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, [2]int64{0, 3}, relay.last())
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestNewRequiresBackend(t *testing.T) {
+	_, err := metax.New("test-api-key")
+	assert.NotNil(t, err)
+}
+
+func TestNewOfflineChainID(t *testing.T) {
+	b, server, err := buildOfflineBcnmy(http.NotFoundHandler())
+	assert.Nil(t, err)
+	defer server.Close()
+
+	chainId, err := b.ChainID(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(80001), chainId.Int64())
+}
+
+func TestNewPreloadedAPIIDs(t *testing.T) {
+	metaAPICalls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		metaAPICalls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api-transfer", r.URL.Query().Get("apiId"))
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	resp, err := b.CheckLimitsContext(context.Background(), "0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "transfer")
+	assert.Nil(t, err)
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, 0, metaAPICalls)
+}
+
+func TestLazyRefresh(t *testing.T) {
+	metaAPICalls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		metaAPICalls++
+		json.NewEncoder(w).Encode(metax.MetaAPIResponse{
+			Flag: 143,
+			ListAPI: []metax.MetaAPIInfo{
+				{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+			},
+		})
+	})
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true})
+	})
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	assert.Equal(t, 0, metaAPICalls)
+
+	_, err = b.CheckLimitsContext(context.Background(), "0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, metaAPICalls)
+
+	assert.Nil(t, b.Refresh(context.Background()))
+	assert.Equal(t, 2, metaAPICalls)
+}
+
+func TestContextCancellation(t *testing.T) {
+	aborted := make(chan bool, 4)
+	relayed := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			aborted <- true
+		case <-time.After(5 * time.Second):
+			aborted <- false
+		}
+	})
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		relayed++
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	// the deadline reaches the HTTP request in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = b.CheckLimitsContext(ctx, "0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "transfer")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.True(t, <-aborted)
+
+	// a cancelled ctx sends nothing
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = b.SubmitWithOpts(ctx, nil, signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, relayed)
+
+	// and ends chain waits
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err = b.WaitConfirmed(ctx, common.HexToHash("0x01"), nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// and the lazy chain setup of a hanging RPC
+	hang := make(chan struct{})
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer rpc.Close()
+	defer close(hang)
+	lazy, err := metax.New("test-api-key", metax.WithRPC(rpc.URL))
+	assert.Nil(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = lazy.VerifyMetaTxContext(ctx, nil, signer.GetAddress().Hex(), nil, &metax.MetaTxMessage{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = lazy.BuildTransactParamsContext(ctx, &metax.MetaTxMessage{}, "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+// decodeRelay decodes the MetaTxMessage and signature of a fake relay request.
+func decodeRelay(t *testing.T, r *http.Request) (*metax.MetaTxRequest, *metax.MetaTxMessage, []byte) {
+	var req metax.MetaTxRequest
+	assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+	raw, _ := json.Marshal(req.Params[0])
+	var metaTxMessage metax.MetaTxMessage
+	assert.Nil(t, json.Unmarshal(raw, &metaTxMessage))
+	signature := hexutil.MustDecode(req.Params[len(req.Params)-1].(string))
+	return &req, &metaTxMessage, signature
+}
+
+func recoverPersonal(t *testing.T, hash common.Hash, sig []byte) common.Address {
+	sig = common.CopyBytes(sig)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(hash.Bytes()), sig)
+	assert.Nil(t, err)
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func TestRawTransactPersonalSign(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		req, metaTxMessage, signature := decodeRelay(t, r)
+		assert.Equal(t, metax.SignaturePersonalType, req.SignatureType)
+		assert.Equal(t, 2, len(req.Params))
+		assert.Equal(t, big.NewInt(7), metaTxMessage.BatchNonce)
+
+		hash, err := metax.PersonalSignHash(metaTxMessage)
+		assert.Nil(t, err)
+		assert.Equal(t, signer.GetAddress(), recoverPersonal(t, hash, signature))
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	chain.setNonce(signer.GetAddress(), 0, 7)
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	resp, tx, receipt, err := b.RawTransactWithOpts(
+		context.Background(),
+		&metax.TransactOpts{SignatureType: metax.SignaturePersonalType},
+		signer,
+		"transfer",
+		common.HexToAddress("0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a"),
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		big.NewInt(1),
+	)
+	assert.Nil(t, err)
+	assert.NotNil(t, tx)
+	assert.Equal(t, resp.TxHash, receipt.TxHash)
+}
+
+func TestEnhanceTransactPersonalSign(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		req, _, _ := decodeRelay(t, r)
+		assert.Equal(t, metax.SignaturePersonalType, req.SignatureType)
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	data, _ := b.Pack("transfer", transferParams()...)
+
+	metaTxMessage := &metax.MetaTxMessage{
+		From:          signer.GetAddress(),
+		To:            common.HexToAddress(offlineDapp),
+		Token:         common.HexToAddress("0x0"),
+		TxGas:         150000,
+		TokenGasPrice: "0",
+		BatchId:       big.NewInt(0),
+		BatchNonce:    big.NewInt(0),
+		Deadline:      big.NewInt(time.Now().Add(time.Hour).Unix()),
+		Data:          hexutil.Encode(data),
+	}
+	hash, err := metax.PersonalSignHash(metaTxMessage)
+	assert.Nil(t, err)
+	signature, err := signer.SignPersonal(hash.Bytes())
+	assert.Nil(t, err)
+
+	opts := &metax.TransactOpts{SignatureType: metax.SignaturePersonalType}
+	_, _, _, err = b.EnhanceTransactWithOpts(context.Background(), opts, signer.GetAddress().Hex(), "transfer", signature, metaTxMessage, common.Hash{}.String())
+	assert.ErrorIs(t, err, metax.ErrSignatureInvalid)
+
+	_, tx, _, err := b.EnhanceTransactWithOpts(context.Background(), opts, signer.GetAddress().Hex(), "transfer", signature, metaTxMessage, hash.String())
+	assert.Nil(t, err)
+	assert.NotNil(t, tx)
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaAPIResponse{Flag: 143})
+	})
+	var observed int32
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRateLimits(metax.RateLimits{
+		Data: metax.RateLimit{RequestsPerSecond: 20, Burst: 1},
+		OnWait: func(group metax.EndpointGroup, wait time.Duration) {
+			assert.Equal(t, metax.DataEndpoints, group)
+			atomic.AddInt32(&observed, 1)
+		},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := b.GetMetaAPI(context.Background())
+		assert.Nil(t, err)
+	}
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&observed))
+
+	stats := b.RateLimiterStats()[metax.DataEndpoints]
+	assert.Equal(t, uint64(5), stats.Requests)
+	assert.True(t, stats.Waited >= 3)
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Equal(t, uint64(0), b.RateLimiterStats()[metax.RelayEndpoints].Requests)
+}
+
+func TestRateLimitMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.CreateDappPublicPath, func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		json.NewEncoder(w).Encode(metax.CreateDappResponse{Code: 200})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRateLimits(metax.RateLimits{
+		Dashboard: metax.RateLimit{MaxInFlight: 2},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := b.CreateDappContext(context.Background(), &metax.CreateDappRequest{DappName: "test"})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, atomic.LoadInt32(&maxInFlight) <= 2)
+}
+
+func TestRateLimitCancelReturnsToken(t *testing.T) {
+	entered, hold := make(chan struct{}, 4), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-hold
+		json.NewEncoder(w).Encode(metax.MetaAPIResponse{Flag: 143})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRateLimits(metax.RateLimits{
+		Data: metax.RateLimit{RequestsPerSecond: 1, Burst: 2, MaxInFlight: 1},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := b.GetMetaAPI(context.Background())
+		done <- err
+	}()
+	<-entered
+
+	// the second request takes the last token, then gives up on the slot
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = b.GetMetaAPI(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(hold)
+	assert.Nil(t, <-done)
+
+	start := time.Now()
+	_, err = b.GetMetaAPI(context.Background())
+	assert.Nil(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+// stubRelayer is a self-hosted relayer answering with err, or mining on chain.
+type stubRelayer struct {
+	chain *fakeChain
+	err   error
+	calls int
+}
+
+func (r *stubRelayer) Name() string {
+	return "stub"
+}
+
+func (r *stubRelayer) Relay(ctx context.Context, req *metax.MetaTxRequest, metaTxMessage *metax.MetaTxMessage) (*metax.MetaTxResponse, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &metax.MetaTxResponse{TxHash: r.chain.mine(nil)}, nil
+}
+
+func TestRelayerFailover(t *testing.T) {
+	var chain *fakeChain
+	v2Calls, v1Calls := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		v2Calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc(metax.MetaTxNativePathV1, func(w http.ResponseWriter, r *http.Request) {
+		v1Calls++
+		json.NewEncoder(w).Encode(metax.MetaTxResponseV1{Flag: 200, Data: metax.MetaTxV1SuccessData{TransactionId: "tx-1"}})
+	})
+	mux.HandleFunc(metax.MetaTransactionStatusPath, func(w http.ResponseWriter, r *http.Request) {
+		var status metax.BiconomyTransaction
+		status.Flag, status.Code = 200, 200
+		status.Data.Receipt.TxHash = chain.mine(nil).Hex()
+		json.NewEncoder(w).Encode(status)
+	})
+	b, chain, server, err := buildFakeChainBcnmy(mux,
+		metax.WithRetryPolicy(metax.NoRetry),
+		metax.WithAPIIDs([]metax.MetaAPIInfo{{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"}}),
+	)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+
+	// only the v2 API by default
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, v2Calls)
+
+	stub := &stubRelayer{chain: chain}
+	b.WithRelayers(nil, b.BiconomyRelayer(), b.BiconomyV1Relayer(), stub)
+	resp, _, receipt, err := b.RawTransact(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, receipt.TxHash, resp.TxHash)
+	assert.Equal(t, 2, v2Calls)
+	assert.Equal(t, 1, v1Calls)
+	assert.Equal(t, 0, stub.calls)
+
+	// per call relayers, failing over to the self-hosted one
+	down := &stubRelayer{err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+	sub, err := b.SubmitWithOpts(context.Background(), &metax.TransactOpts{Relayers: []metax.Relayer{down, stub}}, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, "stub", sub.Relayer)
+	assert.Equal(t, 1, down.calls)
+
+	// the failover stops at a failure the policy rejects
+	stub.err = errors.New("out of gas")
+	never := func(resp *metax.MetaTxResponse, err error) bool { return false }
+	b.WithRelayers(never, down, stub)
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, down.calls)
+	assert.Equal(t, 1, stub.calls)
+
+	b.WithRelayers(nil, down, stub)
+	_, err = b.Submit(signer, "transfer", transferParams()...)
+	var failover *metax.FailoverError
+	assert.ErrorAs(t, err, &failover)
+	assert.Equal(t, 2, len(failover.Attempts))
+	assert.ErrorIs(t, err, down.err)
+	assert.ErrorIs(t, err, stub.err)
+}
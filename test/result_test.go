@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	"github.com/oblzh/bcnmy-go/abi/forwarder"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+const vaultABI = `[
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]},
+	{"type":"function","name":"withdraw","stateMutability":"nonpayable","inputs":[{"name":"amount","type":"uint256"}],"outputs":[]}
+]`
+
+func TestDecodeResult(t *testing.T) {
+	b, chain, server, err := buildFakeChainBcnmy(http.NewServeMux())
+	assert.Nil(t, err)
+	defer server.Close()
+	_, err = b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	_, err = b.RegisterDapp(vaultABI, common.HexToAddress(uniswapDapp))
+	assert.Nil(t, err)
+
+	forwarderABI, _ := forwarder.ForwarderMetaData.GetAbi()
+	parsedVault, _ := abi.JSON(strings.NewReader(vaultABI))
+	insufficient := parsedVault.Errors["InsufficientBalance"]
+	customErr, _ := insufficient.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	customErr = append(common.CopyBytes(insufficient.ID[:4]), customErr...)
+	errorString, _ := (abi.Arguments{{Type: mustType("string")}}).Pack("not enough")
+	errorString = append([]byte{0x08, 0xc3, 0x79, 0xa0}, errorString...)
+
+	var success bool
+	var ret []byte
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		return method.Outputs.Pack(success, ret)
+	}
+	mined := func(dapp string) (*types.Transaction, *types.Receipt) {
+		req := forwarder.ERC20ForwardRequestTypesERC20ForwardRequest{
+			To: common.HexToAddress(dapp), TxGas: big.NewInt(1), TokenGasPrice: big.NewInt(0),
+			BatchId: big.NewInt(0), BatchNonce: big.NewInt(0), Deadline: big.NewInt(0),
+		}
+		data, err := forwarderABI.Pack("executeEIP712", req, common.Hash{}, []byte{})
+		assert.Nil(t, err)
+		tx, _, _ := chain.TransactionByHash(context.Background(), chain.mine(data))
+		receipt, _ := chain.TransactionReceipt(context.Background(), tx.Hash())
+		return tx, receipt
+	}
+
+	// a successful call with its events decoded
+	success, ret = true, []byte{0x1}
+	tx, receipt := mined(offlineDapp)
+	token, from, to := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")
+	parsedDemo, _ := demo.TransferDemoMetaData.GetAbi()
+	amount, _ := parsedDemo.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(7))
+	receipt.Logs = []*types.Log{
+		{
+			Address: common.HexToAddress(offlineDapp),
+			Topics:  []common.Hash{parsedDemo.Events["Transfer"].ID, common.BytesToHash(token.Bytes()), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:    amount,
+		},
+		{Address: common.HexToAddress("0x4"), Topics: []common.Hash{{0x1}}},
+	}
+	result, err := b.DecodeResult(context.Background(), tx, receipt)
+	assert.Nil(t, err)
+	assert.True(t, result.Success)
+	assert.Nil(t, result.Err)
+	assert.Equal(t, []byte{0x1}, result.ReturnData)
+	assert.Len(t, result.Events, 2)
+	assert.Equal(t, "Transfer", result.Events[0].Name)
+	assert.Equal(t, to, result.Events[0].Args["to"])
+	assert.Equal(t, big.NewInt(7), result.Events[0].Args["amount"])
+	assert.Equal(t, "", result.Events[1].Name)
+
+	// a failed call with Error(string)
+	success, ret = false, errorString
+	tx, receipt = mined(offlineDapp)
+	result, err = b.DecodeResult(context.Background(), tx, receipt)
+	assert.Nil(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, "Error", result.Err.Name)
+	assert.Equal(t, "not enough", result.Err.Reason)
+
+	// a failed call with a custom error of the target dapp
+	success, ret = false, customErr
+	tx, receipt = mined(uniswapDapp)
+	result, err = b.DecodeResult(context.Background(), tx, receipt)
+	assert.Nil(t, err)
+	assert.Equal(t, "InsufficientBalance", result.Err.Name)
+	assert.Equal(t, big.NewInt(2), result.Err.Args["required"])
+
+	panicData := append([]byte{0x4e, 0x48, 0x7b, 0x71}, common.LeftPadBytes([]byte{0x11}, 32)...)
+	var callErr *metax.CallError = b.DecodeError(nil, panicData)
+	assert.Equal(t, "Panic", callErr.Name)
+	assert.Equal(t, big.NewInt(0x11), callErr.PanicCode)
+	assert.Equal(t, "Reverted with data 0x12345678", b.DecodeError(nil, []byte{0x12, 0x34, 0x56, 0x78}).Error())
+}
+
+func mustType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
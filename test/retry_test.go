@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+var fastRetry = &metax.ExponentialBackoff{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	Multiplier:     2,
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(metax.MetaAPIResponse{Flag: 143})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRetryPolicy(fastRetry))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	_, err = b.GetMetaAPI(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryRelayNotResent(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRetryPolicy(fastRetry))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	_, err = b.SendMetaNativeTxContext(context.Background(), &metax.MetaTxRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryRelayRateLimited(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var req metax.MetaTxRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "api-transfer", req.ApiID)
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: common.HexToHash("0x01")})
+	})
+	b, server, err := buildOfflineBcnmy(mux, metax.WithRetryPolicy(fastRetry))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	resp, err := b.SendMetaNativeTxContext(context.Background(), &metax.MetaTxRequest{ApiID: "api-transfer"})
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToHash("0x01"), resp.TxHash)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTransactionStatusPolls(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTransactionStatusPath, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		resp := metax.BiconomyTransaction{Code: 200}
+		if calls == 3 {
+			resp.Data.Receipt.TxHash = common.HexToHash("0x02").Hex()
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	b, server, err := buildOfflineBcnmy(mux)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithSleepTimeSec(0)
+
+	resp, err := b.GetTransactionStatusContext(context.Background(), "tx-id")
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToHash("0x02").Hex(), resp.Data.Receipt.TxHash)
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestSelfRelayFallback(t *testing.T) {
+	status := http.StatusInternalServerError
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		switch status {
+		case http.StatusOK:
+			json.NewEncoder(w).Encode(metax.MetaTxResponse{Code: metax.DappLimitExhaustedCode, Message: "DApp limit reached"})
+		case http.StatusBadRequest:
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(metax.MetaTxResponse{Code: status, Message: "Invalid signature"})
+		default:
+			w.WriteHeader(status)
+		}
+	})
+	relayer := metax.NewSignerFromKey(relayerKey)
+	// the simulated backend signs for chain 1337
+	b, chain, server, err := buildFakeChainBcnmy(mux,
+		metax.WithChainID(big.NewInt(1337)),
+		metax.WithForwarderAddress(metax.ForwarderAddressMap["80001"]),
+		metax.WithRetryPolicy(metax.NoRetry),
+		metax.WithSelfRelay(metax.SelfRelayConfig{Signer: relayer, MaxGasPrice: big.NewInt(5e9)}),
+		metax.WithAPIIDs([]metax.MetaAPIInfo{{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"}}),
+	)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+
+	// Biconomy down: the relayer account sends executeEIP712 itself
+	var txs []*types.Transaction
+	for i := 0; i < 2; i++ {
+		sub, err := b.Submit(signer, "transfer", transferParams()...)
+		assert.Nil(t, err)
+		assert.Equal(t, metax.RelayerSelf, sub.Relayer)
+		tx, _, err := chain.TransactionByHash(context.Background(), sub.TxHash)
+		assert.Nil(t, err)
+		txs = append(txs, tx)
+	}
+	chain.Commit()
+	for i, tx := range txs {
+		assert.Equal(t, uint64(i), tx.Nonce())
+		assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+		assert.True(t, tx.GasFeeCap().Cmp(big.NewInt(5e9)) <= 0)
+		assert.Equal(t, metax.ForwarderAddressMap["80001"], *tx.To())
+		receipt, err := chain.TransactionReceipt(context.Background(), tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	}
+
+	// exhausted limits fall back too
+	status = http.StatusOK
+	resp, tx, receipt, err := b.RawTransactWithOpts(context.Background(), &metax.TransactOpts{OnStatus: func(update metax.StatusUpdate) {
+		if update.Status == metax.TxStatusRelayed {
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				chain.Commit()
+			}()
+		}
+	}}, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(), resp.TxHash)
+	assert.Equal(t, uint64(2), tx.Nonce())
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	// rejected requests are not self relayed
+	status = http.StatusBadRequest
+	sub, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrRelayerRejected)
+	assert.Equal(t, metax.RelayerBiconomy, sub.Relayer)
+}
+
+func TestSelfRelayMaxGasPrice(t *testing.T) {
+	b, chain, server, err := buildFakeChainBcnmy(http.NotFoundHandler(),
+		metax.WithChainID(big.NewInt(1337)),
+		metax.WithForwarderAddress(metax.ForwarderAddressMap["80001"]),
+		metax.WithAPIIDs([]metax.MetaAPIInfo{{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"}}),
+	)
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	relayer := metax.NewSignerFromKey(relayerKey)
+
+	reservation, err := b.ReserveNonce(context.Background(), signer.GetAddress())
+	assert.Nil(t, err)
+	reservation.Fail()
+	// a max below the base fee or the suggested price could never be mined
+	for _, pricing := range []metax.GasPricing{metax.GasPricingAuto, metax.GasPricingLegacy} {
+		self, err := b.SelfRelayer(metax.SelfRelayConfig{Signer: relayer, GasPricing: pricing, MaxGasPrice: big.NewInt(1)})
+		assert.Nil(t, err)
+		_, err = b.SubmitWithOpts(context.Background(), &metax.TransactOpts{Relayers: []metax.Relayer{self}}, signer, "transfer", transferParams()...)
+		assert.ErrorIs(t, err, metax.ErrGasPriceAboveMax)
+	}
+	sent, err := chain.PendingNonceAt(context.Background(), relayer.GetAddress())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), sent)
+
+	// the request nonce is handed out again
+	again, err := b.ReserveNonce(context.Background(), signer.GetAddress())
+	assert.Nil(t, err)
+	assert.Equal(t, reservation.BatchNonce, again.BatchNonce)
+	again.Fail()
+}
+
+func TestSelfRelayPolicy(t *testing.T) {
+	// a timeout may have been relayed, a failed dial was not
+	assert.False(t, metax.DefaultFallbackPolicy(nil, context.DeadlineExceeded))
+	assert.False(t, metax.DefaultFallbackPolicy(nil, io.ErrUnexpectedEOF))
+	assert.True(t, metax.DefaultFallbackPolicy(nil, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}))
+	assert.True(t, metax.DefaultFallbackPolicy(nil, &net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.False(t, metax.DefaultFallbackPolicy(nil, context.Canceled))
+	assert.True(t, metax.DefaultFallbackPolicy(nil, &metax.HTTPStatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, metax.DefaultFallbackPolicy(nil, &metax.HTTPStatusError{StatusCode: http.StatusUnauthorized}))
+	resp := &metax.MetaTxResponse{Code: 417}
+	assert.False(t, metax.DefaultFallbackPolicy(resp, &metax.RelayerError{Response: resp}))
+	resp = &metax.MetaTxResponse{Code: 503}
+	assert.True(t, metax.DefaultFallbackPolicy(resp, &metax.RelayerError{Response: resp}))
+
+	b, server, err := buildOfflineBcnmy(http.NotFoundHandler())
+	assert.Nil(t, err)
+	defer server.Close()
+	_, err = b.SelfRelay(&metax.MetaTxMessage{}, nil)
+	assert.NotNil(t, err)
+	_, err = metax.New("test-api-key", metax.WithSelfRelay(metax.SelfRelayConfig{}))
+	assert.NotNil(t, err)
+}
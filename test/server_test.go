@@ -0,0 +1,330 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+	"github.com/oblzh/bcnmy-go/metax/server"
+)
+
+func postJSON(t *testing.T, url string, body interface{}, v interface{}) int {
+	var reader io.Reader
+	if raw, ok := body.(string); ok {
+		reader = strings.NewReader(raw)
+	} else {
+		encoded, err := json.Marshal(body)
+		assert.Nil(t, err)
+		reader = bytes.NewReader(encoded)
+	}
+	resp, err := http.Post(url, "application/json", reader)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if v != nil {
+		json.NewDecoder(resp.Body).Decode(v)
+	}
+	return resp.StatusCode
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(v)
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true, Message: r.URL.Query().Get("apiId")})
+	})
+	b, chain, api, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+		{ContractAddress: offlineDapp, Method: "transferOwnership", ID: "api-owner"},
+		{ContractAddress: uniswapDapp, Method: "isTrustedForwarder", ID: "api-uniswap"},
+	}))
+	assert.Nil(t, err)
+	defer api.Close()
+	dapp := common.HexToAddress(offlineDapp)
+	b.RegisterDapp(demo.TransferDemoABI, dapp)
+	b.RegisterDapp(demo.UniswapDemoABI, common.HexToAddress(uniswapDapp))
+
+	_, err = server.New(b, server.Config{Policy: server.Policy{common.HexToAddress("0x01"): nil}})
+	assert.NotNil(t, err)
+	gateway, err := server.New(b, server.Config{
+		AllowedOrigins: []string{"https://app.example"},
+		Policy:         server.Policy{dapp: {"transfer"}},
+	})
+	assert.Nil(t, err)
+	defer gateway.Close()
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	// typed data, signed by the frontend, submitted and followed by id
+	request := map[string]interface{}{
+		"dapp":   dapp,
+		"from":   signer.GetAddress(),
+		"method": "transfer",
+		"params": []interface{}{"0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "1"},
+	}
+	var payload metax.ForwardRequestPayload
+	assert.Equal(t, http.StatusOK, postJSON(t, srv.URL+"/v1/typed-data", request, &payload))
+	assert.Equal(t, "transfer(address,address,uint256)", payload.Method)
+	assert.Equal(t, dapp, payload.Message.To)
+	signature, err := signer.SignTypedData(payload.TypedData)
+	assert.Nil(t, err)
+
+	var wrong server.ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: &payload, Signature: make([]byte, 65)}, &wrong))
+	assert.NotEmpty(t, wrong.Error)
+
+	var status server.StatusResponse
+	assert.Equal(t, http.StatusAccepted, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: &payload, Signature: signature}, &status))
+	assert.NotEmpty(t, status.ID)
+	assert.NotEqual(t, common.Hash{}, status.TxHash)
+	assert.Eventually(t, func() bool {
+		var current server.StatusResponse
+		getJSON(t, srv.URL+"/v1/status/"+status.ID, &current)
+		return current.Status == metax.TxStatusConfirmed && current.BlockNumber > 0
+	}, 5*time.Second, 20*time.Millisecond)
+	var missing server.ErrorResponse
+	assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/v1/status/unknown", &missing))
+
+	// limits
+	var limits metax.CheckLimitResponse
+	assert.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/limits?dapp="+offlineDapp+"&from="+signer.GetAddress().Hex()+"&method=transfer", &limits))
+	assert.True(t, limits.Allowed)
+	assert.Equal(t, "api-transfer", limits.Message)
+
+	// policy
+	var denied server.ErrorResponse
+	request["dapp"] = common.HexToAddress(uniswapDapp)
+	request["method"] = "isTrustedForwarder"
+	request["params"] = []interface{}{"0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a"}
+	assert.Equal(t, http.StatusForbidden, postJSON(t, srv.URL+"/v1/typed-data", request, &denied))
+	assert.Contains(t, denied.Error, "Not allowed")
+	// the policy follows the signed calldata, whatever the payload's method says
+	transfer, _ := b.Dapp(dapp)
+	owner, err := transfer.BuildForwardRequest(signer.GetAddress(), "transferOwnership", signer.GetAddress())
+	assert.Nil(t, err)
+	signature, _ = signer.SignTypedData(owner.TypedData)
+	owner.Method = "transfer"
+	assert.Equal(t, http.StatusForbidden, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: owner, Signature: signature}, &denied))
+	owner.Message.Data = "0xdeadbeef"
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: owner, Signature: signature}, &denied))
+	assert.Equal(t, http.StatusForbidden, getJSON(t, srv.URL+"/v1/limits?dapp="+uniswapDapp+"&from="+signer.GetAddress().Hex()+"&method=isTrustedForwarder", &denied))
+
+	// malformed requests
+	var invalid server.ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, srv.URL+"/v1/typed-data", `{"dapp":`, &invalid))
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, srv.URL+"/v1/typed-data", `{"dapp":"`+offlineDapp+`","unknown":1}`, &invalid))
+	request["dapp"] = dapp
+	request["method"] = "transfer"
+	request["params"] = []interface{}{"0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "not an address", "1"}
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, srv.URL+"/v1/typed-data", request, &invalid))
+	resp, err := http.Get(srv.URL + "/v1/submit")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// CORS
+	preflight, _ := http.NewRequest(http.MethodOptions, srv.URL+"/v1/submit", nil)
+	preflight.Header.Set("Origin", "https://app.example")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	resp, err = http.DefaultClient.Do(preflight)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPost)
+	preflight.Header.Set("Origin", "https://evil.example")
+	resp, err = http.DefaultClient.Do(preflight)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// schemas
+	var schema map[string]interface{}
+	assert.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/schemas/submit-request.json", &schema))
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/v1/schemas/missing.json", &schema))
+}
+
+func TestServerLimits(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, api, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer api.Close()
+	dapp := common.HexToAddress(offlineDapp)
+	b.RegisterDapp(demo.TransferDemoABI, dapp)
+	gateway, err := server.New(b, server.Config{
+		ClientLimit: server.Limit{PerSecond: 0.001, Burst: 6},
+		FromLimit:   server.Limit{PerSecond: 0.001, Burst: 4},
+	})
+	assert.Nil(t, err)
+	defer gateway.Close()
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	typedData := func(from common.Address) (*metax.ForwardRequestPayload, int) {
+		var payload metax.ForwardRequestPayload
+		status := postJSON(t, srv.URL+"/v1/typed-data", map[string]interface{}{
+			"dapp":   dapp,
+			"from":   from,
+			"method": "transfer",
+			"params": []interface{}{"0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "1"},
+		}, &payload)
+		return &payload, status
+	}
+
+	// each payload holds its nonce, one submitted again conflicts
+	first, status := typedData(signer.GetAddress())
+	assert.Equal(t, http.StatusOK, status)
+	second, status := typedData(signer.GetAddress())
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEqual(t, first.Message.BatchNonce, second.Message.BatchNonce)
+	signature, _ := signer.SignTypedData(first.TypedData)
+	assert.Equal(t, http.StatusAccepted, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: first, Signature: signature}, nil))
+	chain.setNonce(signer.GetAddress(), 0, first.Message.BatchNonce.Int64()+1)
+	var conflict server.ErrorResponse
+	assert.Equal(t, http.StatusConflict, postJSON(t, srv.URL+"/v1/submit", server.SubmitRequest{Payload: first, Signature: signature}, &conflict))
+	assert.NotEmpty(t, conflict.Error)
+
+	// per signer, then per client
+	_, status = typedData(signer.GetAddress())
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	other := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	_, status = typedData(other)
+	assert.Equal(t, http.StatusOK, status)
+	_, status = typedData(other)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	// oversized bodies are refused
+	huge := `{"dapp":"` + offlineDapp + `","method":"` + strings.Repeat("a", server.DefaultMaxBodyBytes) + `"}`
+	unlimited, err := server.New(b, server.Config{ClientLimit: server.Limit{PerSecond: -1}})
+	assert.Nil(t, err)
+	defer unlimited.Close()
+	unlimitedSrv := httptest.NewServer(unlimited)
+	defer unlimitedSrv.Close()
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, unlimitedSrv.URL+"/v1/typed-data", huge, nil))
+}
+
+func TestServerParams(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.CheckLimitPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.CheckLimitResponse{Code: 200, Allowed: true, Message: r.URL.Query().Get("apiId")})
+	})
+	b, _, api, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "mint(uint256)", ID: "api-mint"},
+	}))
+	assert.Nil(t, err)
+	defer api.Close()
+	dapp, err := b.RegisterDapp(overloadedABI, common.HexToAddress(offlineDapp))
+	assert.Nil(t, err)
+	gateway, err := server.New(b, server.Config{})
+	assert.Nil(t, err)
+	defer gateway.Close()
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+	from := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	// integers are decimal unless 0x prefixed
+	mint := func(amount string) (*metax.ForwardRequestPayload, int) {
+		var payload metax.ForwardRequestPayload
+		status := postJSON(t, srv.URL+"/v1/typed-data", map[string]interface{}{
+			"dapp": dapp.Address(), "from": from, "method": "mint(uint256)", "params": []interface{}{amount},
+		}, &payload)
+		return &payload, status
+	}
+	ten, _ := dapp.Pack("mint(uint256)", big.NewInt(10))
+	for _, amount := range []string{"010", "10", "0xa"} {
+		payload, status := mint(amount)
+		assert.Equal(t, http.StatusOK, status, amount)
+		assert.Equal(t, hexutil.Encode(ten), payload.Message.Data, amount)
+	}
+	for _, amount := range []string{"1_000", "0o10", "0b10", "0x0a", "1e3"} {
+		_, status := mint(amount)
+		assert.Equal(t, http.StatusBadRequest, status, amount)
+	}
+
+	// overloaded names need the signature or selector without params
+	limits := func(method string) (metax.CheckLimitResponse, int) {
+		var resp metax.CheckLimitResponse
+		status := getJSON(t, srv.URL+"/v1/limits?dapp="+offlineDapp+"&from="+from.Hex()+"&method="+url.QueryEscape(method), &resp)
+		return resp, status
+	}
+	var ambiguous server.ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, srv.URL+"/v1/limits?dapp="+offlineDapp+"&from="+from.Hex()+"&method=mint", &ambiguous))
+	assert.Contains(t, ambiguous.Error, "use the signature or selector")
+	m, _ := dapp.Method("mint(uint256)")
+	for _, method := range []string{"mint(uint256)", hexutil.Encode(m.ID)} {
+		resp, status := limits(method)
+		assert.Equal(t, http.StatusOK, status, method)
+		assert.Equal(t, "api-mint", resp.Message, method)
+	}
+}
+
+func TestServerMaxTracked(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	relaying, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, func(w http.ResponseWriter, r *http.Request) {
+		close(relaying)
+		<-release
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	b, chain, api, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer api.Close()
+	dapp, _ := b.RegisterDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	gateway, err := server.New(b, server.Config{MaxTracked: 1})
+	assert.Nil(t, err)
+	defer gateway.Close()
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	payload, err := dapp.BuildForwardRequest(signer.GetAddress(), "transfer", transferParams()...)
+	assert.Nil(t, err)
+	signature, _ := signer.SignTypedData(payload.TypedData)
+	submit := server.SubmitRequest{Payload: payload, Signature: signature}
+	accepted := make(chan int)
+	go func() {
+		accepted <- postJSON(t, srv.URL+"/v1/submit", submit, nil)
+	}()
+	// the submit still relaying holds the only slot
+	<-relaying
+	var full server.ErrorResponse
+	assert.Equal(t, http.StatusServiceUnavailable, postJSON(t, srv.URL+"/v1/submit", submit, &full))
+	assert.NotEmpty(t, full.Error)
+	close(release)
+	assert.Equal(t, http.StatusAccepted, <-accepted)
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func testTypedData(from common.Address) apitypes.TypedData {
+	metaTxMessage := &metax.MetaTxMessage{
+		From:          from,
+		To:            common.HexToAddress(offlineDapp),
+		Token:         common.HexToAddress("0x0"),
+		TxGas:         21000,
+		TokenGasPrice: "0",
+		BatchId:       big.NewInt(0),
+		BatchNonce:    big.NewInt(0),
+		Deadline:      big.NewInt(1684116992),
+		Data:          "0x",
+	}
+	return apitypes.TypedData{
+		Types:       metax.SignedTypes,
+		PrimaryType: metax.ForwardRequestType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              metax.ForwardRequestName,
+			Version:           metax.Version,
+			VerifyingContract: common.HexToAddress("0x69015912AA33720b842dCD6aC059Ed623F28d9f7").Hex(),
+			Salt:              hexutil.Encode(common.LeftPadBytes(big.NewInt(80001).Bytes(), 32)),
+		},
+		Message: metaTxMessage.TypedData(),
+	}
+}
+
+func recoverTypedData(t *testing.T, typedData apitypes.TypedData, sig []byte) common.Address {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	assert.Nil(t, err)
+	sig = common.CopyBytes(sig)
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	assert.Nil(t, err)
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func TestMnemonicSigner(t *testing.T) {
+	signer, err := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), signer.GetAddress())
+
+	signer, err = metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/1")
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), signer.GetAddress())
+
+	typedData := testTypedData(signer.GetAddress())
+	sig, err := signer.SignTypedData(typedData)
+	assert.Nil(t, err)
+	assert.Equal(t, signer.GetAddress(), recoverTypedData(t, typedData, sig))
+
+	_, err = metax.NewSignerFromMnemonic("test test", "", "m/44'/60'/0'/0/0")
+	assert.NotNil(t, err)
+	// the checksum catches a mistyped word
+	_, err = metax.NewSignerFromMnemonic("test test test test test test test test test test test test", "", "m/44'/60'/0'/0/0")
+	assert.NotNil(t, err)
+	_, err = metax.NewSignerFromMnemonic("test test test test test test test test test test test junkk", "", "m/44'/60'/0'/0/0")
+	assert.NotNil(t, err)
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := crypto.GenerateKey()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(key, "passwd")
+	assert.Nil(t, err)
+
+	_, err = metax.NewKeystoreSignerFrom(ks, account.Address, "wrong")
+	assert.NotNil(t, err)
+
+	signer, err := metax.NewKeystoreSignerFrom(ks, account.Address, "passwd")
+	assert.Nil(t, err)
+	defer signer.Lock()
+
+	var _ metax.TypedDataSigner = signer
+	typedData := testTypedData(signer.GetAddress())
+	sig, err := signer.SignTypedData(typedData)
+	assert.Nil(t, err)
+	assert.Equal(t, account.Address, recoverTypedData(t, typedData, sig))
+}
+
+// fakeClef implements the account_signTypedData method of Clef.
+type fakeClef struct {
+	signer *metax.Signer
+}
+
+func (c *fakeClef) SignTypedData(addr common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	return c.signer.SignTypedData(typedData)
+}
+
+func TestExternalSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	inner := metax.NewSignerFromKey(key)
+	server := rpc.NewServer()
+	assert.Nil(t, server.RegisterName("account", &fakeClef{signer: inner}))
+	defer server.Stop()
+
+	signer := metax.NewExternalSignerFrom(rpc.DialInProc(server), inner.GetAddress(), 0)
+	defer signer.Close()
+	typedData := testTypedData(signer.GetAddress())
+	sig, err := signer.SignTypedData(typedData)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, inner.GetAddress(), recoverTypedData(t, typedData, sig))
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+// revertError mimics the JSON-RPC error of a reverted eth_call.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+func revertReason(reason string) []byte {
+	typ, _ := abi.NewType("string", "", nil)
+	data, _ := abi.Arguments{{Type: typ}}.Pack(reason)
+	return append(crypto.Keccak256([]byte("Error(string)"))[:4], data...)
+}
+
+func TestSimulate(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, chain, server, err := buildFakeChainBcnmy(http.NotFoundHandler())
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	metaTxMessage := &metax.MetaTxMessage{
+		From:          signer.GetAddress(),
+		To:            common.HexToAddress(offlineDapp),
+		Token:         common.HexToAddress("0x0"),
+		TxGas:         21000,
+		TokenGasPrice: "0",
+		BatchId:       big.NewInt(0),
+		BatchNonce:    big.NewInt(0),
+		Deadline:      big.NewInt(time.Now().Add(time.Hour).Unix()),
+		Data:          "0x",
+	}
+	signature := []byte{0x1}
+
+	var executed []interface{}
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		switch method.Name {
+		case "verifyEIP712":
+			return nil, nil
+		case "executeEIP712":
+			executed = args
+			return method.Outputs.Pack(true, []byte{0x2a})
+		}
+		return nil, errors.New("unexpected call")
+	}
+	result, err := b.Simulate(metaTxMessage, signature)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Err())
+	assert.Equal(t, metax.DefaultSimulationRelayer, result.From)
+	assert.Equal(t, []byte{0x2a}, result.ReturnData)
+	assert.Equal(t, signature, executed[2])
+
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		if method.Name == "verifyEIP712" {
+			return nil, &revertError{data: revertReason("signature mismatch")}
+		}
+		return nil, errors.New("unexpected call")
+	}
+	result, err = b.Simulate(metaTxMessage, signature)
+	assert.Nil(t, err)
+	assert.False(t, result.Verified)
+	assert.Equal(t, "signature mismatch", result.RevertReason)
+	assert.ErrorIs(t, result.Err(), metax.ErrSignatureInvalid)
+
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		if method.Name == "verifyEIP712" {
+			return nil, nil
+		}
+		return nil, &revertError{data: revertReason("ERC20: transfer amount exceeds balance")}
+	}
+	result, err = b.Simulate(metaTxMessage, signature)
+	assert.Nil(t, err)
+	assert.True(t, result.Verified)
+	assert.False(t, result.Success)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", result.RevertReason)
+	assert.ErrorIs(t, result.Err(), metax.ErrSimulationReverted)
+	assert.NotErrorIs(t, result.Err(), metax.ErrSignatureInvalid)
+
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	_, err = b.Simulate(metaTxMessage, signature)
+	assert.NotNil(t, err)
+}
+
+func TestRawTransactSimulateBeforeRelay(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	relayed := false
+	b, chain, server, err := buildFakeChainBcnmy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		relayed = true
+	}), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	relayer := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	chain.callHook = func(method *abi.Method, args []interface{}) ([]byte, error) {
+		if method.Name == "verifyEIP712" {
+			return nil, nil
+		}
+		return nil, &revertError{data: revertReason("Not enough tokens")}
+	}
+	_, _, _, err = b.RawTransactWithOpts(
+		context.Background(),
+		&metax.TransactOpts{Simulate: true, SimulationFrom: relayer},
+		signer,
+		"transfer",
+		common.HexToAddress("0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a"),
+		relayer,
+		big.NewInt(1),
+	)
+	assert.ErrorIs(t, err, metax.ErrSimulationReverted)
+	var simErr *metax.SimulationError
+	assert.True(t, errors.As(err, &simErr))
+	assert.Equal(t, relayer, simErr.Result.From)
+	assert.Equal(t, "Not enough tokens", simErr.Result.RevertReason)
+	assert.False(t, relayed)
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func buildSubmitBcnmy(t *testing.T, relay http.HandlerFunc) (*metax.Bcnmy, *fakeChain, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(metax.MetaTxNativePath, relay)
+	b, chain, server, err := buildFakeChainBcnmy(mux, metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	return b, chain, server.Close
+}
+
+func transferParams() []interface{} {
+	return []interface{}{
+		common.HexToAddress("0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a"),
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+		big.NewInt(1),
+	}
+}
+
+func TestSubmitAndWait(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	b, chain, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	defer stop()
+
+	var mu sync.Mutex
+	var callbacks []metax.TxStatus
+	opts := &metax.TransactOpts{OnStatus: func(update metax.StatusUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		callbacks = append(callbacks, update.Status)
+	}}
+	sub, err := b.SubmitWithOpts(context.Background(), opts, signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, metax.TxStatusRelayed, sub.Status())
+	assert.Equal(t, sub.Response.TxHash, sub.TxHash)
+	assert.Equal(t, "api-transfer", sub.Request.ApiID)
+	assert.False(t, sub.SubmittedAt.IsZero())
+	updates := sub.Updates()
+
+	// receipt in block 1, head at 3
+	chain.Commit()
+	chain.Commit()
+	chain.Commit()
+	tx, receipt, err := sub.Wait(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, sub.TxHash, tx.Hash())
+	assert.Equal(t, sub.TxHash, receipt.TxHash)
+	assert.Equal(t, metax.TxStatusConfirmed, sub.Status())
+
+	var statuses []metax.TxStatus
+	for update := range updates {
+		statuses = append(statuses, update.Status)
+	}
+	expected := []metax.TxStatus{metax.TxStatusRelayed, metax.TxStatusMined, metax.TxStatusConfirmed}
+	assert.Equal(t, expected, statuses)
+	mu.Lock()
+	assert.Equal(t, expected, callbacks)
+	mu.Unlock()
+
+	// a late subscriber gets the history and a closed channel
+	statuses = nil
+	for update := range sub.Updates() {
+		statuses = append(statuses, update.Status)
+	}
+	assert.Equal(t, expected, statuses)
+}
+
+func TestSubmitFailed(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	b, chain, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		txHash := chain.mine(nil)
+		chain.revert(txHash)
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: txHash})
+	})
+	defer stop()
+
+	_, _, receipt, err := b.RawTransact(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	assert.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+
+	sub, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	_, _, err = sub.Wait(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, metax.TxStatusFailed, sub.Status())
+}
+
+func TestSubmitRejected(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, _, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{Code: 417, Message: "Not allowed"})
+	})
+	defer stop()
+
+	sub, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrRelayerRejected)
+	assert.Equal(t, metax.TxStatusFailed, sub.Status())
+	assert.Equal(t, 417, sub.Response.Code)
+
+	resp, _, _, err := b.RawTransact(signer, "transfer", transferParams()...)
+	assert.ErrorIs(t, err, metax.ErrRelayerRejected)
+	assert.Equal(t, "Not allowed", resp.Message)
+}
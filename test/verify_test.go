@@ -0,0 +1,125 @@
+package test
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+
+	demo "github.com/oblzh/bcnmy-go/abi/demo"
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+func TestVerifyMetaTx(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	b, server, err := buildOfflineBcnmy(http.NotFoundHandler())
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+
+	message := func() *metax.MetaTxMessage {
+		return &metax.MetaTxMessage{
+			From:          signer.GetAddress(),
+			To:            common.HexToAddress(offlineDapp),
+			Token:         common.HexToAddress("0x0"),
+			TxGas:         21000,
+			TokenGasPrice: "0",
+			BatchId:       big.NewInt(0),
+			BatchNonce:    big.NewInt(3),
+			Deadline:      big.NewInt(time.Now().Add(time.Hour).Unix()),
+			Data:          "0x",
+		}
+	}
+	sign := func(m *metax.MetaTxMessage, forwarder common.Address) []byte {
+		sig, err := signer.SignTypedData(apitypes.TypedData{
+			Types:       metax.SignedTypes,
+			PrimaryType: metax.ForwardRequestType,
+			Domain: apitypes.TypedDataDomain{
+				Name:              metax.ForwardRequestName,
+				Version:           metax.Version,
+				VerifyingContract: forwarder.Hex(),
+				Salt:              hexutil.Encode(common.LeftPadBytes(big.NewInt(80001).Bytes(), 32)),
+			},
+			Message: m.TypedData(),
+		})
+		assert.Nil(t, err)
+		return sig
+	}
+	from := signer.GetAddress().Hex()
+	forwarder := metax.ForwarderAddressMap["80001"]
+
+	m := message()
+	sig := sign(m, forwarder)
+	assert.Nil(t, b.VerifyMetaTx(nil, from, sig, m))
+
+	// v as 0/1 is accepted as well
+	lowV := common.CopyBytes(sig)
+	lowV[64] -= 27
+	assert.Nil(t, b.VerifyMetaTx(nil, from, lowV, m))
+
+	// malleated high-s twin of a valid signature
+	highS := common.CopyBytes(sig)
+	s := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+	copy(highS[32:64], common.LeftPadBytes(s.Bytes(), 32))
+	highS[64] ^= 1
+	assert.ErrorIs(t, b.VerifyMetaTx(nil, from, highS, m), metax.ErrSignatureInvalid)
+
+	err = b.VerifyMetaTx(nil, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", sig, m)
+	assert.ErrorIs(t, err, metax.ErrSignatureInvalid)
+
+	tampered := message()
+	tampered.BatchNonce = big.NewInt(4)
+	assert.ErrorIs(t, b.VerifyMetaTx(nil, from, sig, tampered), metax.ErrSignatureInvalid)
+
+	otherDomain := message()
+	assert.ErrorIs(t, b.VerifyMetaTx(nil, from, sign(otherDomain, metax.ForwarderAddressMap["5"]), otherDomain), metax.ErrSignatureInvalid)
+
+	otherDapp := message()
+	otherDapp.To = common.HexToAddress("0x96774c64dc3f46f64d17034ce6cf7b2ef31da56a")
+	assert.ErrorIs(t, b.VerifyMetaTx(nil, from, sign(otherDapp, forwarder), otherDapp), metax.ErrDappMismatch)
+
+	expired := message()
+	expired.Deadline = big.NewInt(time.Now().Add(-time.Minute).Unix())
+	assert.ErrorIs(t, b.VerifyMetaTx(nil, from, sign(expired, forwarder), expired), metax.ErrDeadlineExpired)
+}
+
+func TestEnhanceTransactRejectsBeforeRelay(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	other, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/1")
+	relayed := false
+	b, server, err := buildOfflineBcnmy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { relayed = true }), metax.WithAPIIDs([]metax.MetaAPIInfo{
+		{ContractAddress: offlineDapp, Method: "transfer", ID: "api-transfer"},
+	}))
+	assert.Nil(t, err)
+	defer server.Close()
+	b.WithDapp(demo.TransferDemoABI, common.HexToAddress(offlineDapp))
+	data, _ := b.Pack("transfer", transferParams()...)
+
+	metaTxMessage := &metax.MetaTxMessage{
+		From:          signer.GetAddress(),
+		To:            common.HexToAddress(offlineDapp),
+		Token:         common.HexToAddress("0x0"),
+		TxGas:         21000,
+		TokenGasPrice: "0",
+		BatchId:       big.NewInt(0),
+		BatchNonce:    big.NewInt(0),
+		Deadline:      big.NewInt(time.Now().Add(time.Hour).Unix()),
+		Data:          hexutil.Encode(data),
+	}
+	typedData := testTypedData(signer.GetAddress())
+	typedData.Domain.VerifyingContract = metax.ForwarderAddressMap["80001"].Hex()
+	typedData.Message = metaTxMessage.TypedData()
+	typedDataHash, _ := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	signature, err := other.SignTypedData(typedData)
+	assert.Nil(t, err)
+
+	_, _, _, err = b.EnhanceTransact(signer.GetAddress().Hex(), "transfer", signature, metaTxMessage, typedDataHash.String())
+	assert.ErrorIs(t, err, metax.ErrSignatureInvalid)
+	assert.False(t, relayed)
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metax "github.com/oblzh/bcnmy-go/metax"
+)
+
+// waitFor reads updates until status, failing on a final status first.
+func waitFor(t *testing.T, updates <-chan metax.StatusUpdate, status metax.TxStatus) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.Status == status {
+				return
+			}
+			if update.Status.Final() {
+				t.Fatalf("got %s waiting for %s", update.Status, status)
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for %s", status)
+		}
+	}
+}
+
+func TestWaitReorg(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	b, chain, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	defer stop()
+	waitOpts := &metax.WaitOpts{Confirmations: 3, PollInterval: 10 * time.Millisecond}
+
+	// reorged out of block 1, mined again in block 2
+	sub, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	updates := sub.Updates()
+	chain.Commit()
+	type waited struct {
+		number int64
+		err    error
+	}
+	done := make(chan waited, 1)
+	go func() {
+		_, receipt, err := sub.WaitWithOpts(context.Background(), waitOpts)
+		if err != nil {
+			done <- waited{err: err}
+			return
+		}
+		done <- waited{number: receipt.BlockNumber.Int64()}
+	}()
+	waitFor(t, updates, metax.TxStatusMined)
+	chain.reorgOut(sub.TxHash)
+	waitFor(t, updates, metax.TxStatusReorged)
+	// every reorg reports the transitions again
+	chain.include(sub.TxHash, 1)
+	waitFor(t, updates, metax.TxStatusMined)
+	chain.reorgOut(sub.TxHash)
+	waitFor(t, updates, metax.TxStatusReorged)
+	chain.include(sub.TxHash, 2)
+	chain.Commit()
+	chain.Commit()
+	chain.Commit()
+	result := <-done
+	assert.Nil(t, result.err)
+	assert.Equal(t, int64(2), result.number)
+	assert.Equal(t, metax.TxStatusConfirmed, sub.Status())
+
+	// reorged out and forgotten by the node before its block is deep enough
+	sub, err = b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	chain.include(sub.TxHash, 5)
+	updates = sub.Updates()
+	go func() {
+		_, _, err := sub.WaitWithOpts(context.Background(), waitOpts)
+		done <- waited{err: err}
+	}()
+	waitFor(t, updates, metax.TxStatusMined)
+	chain.drop(sub.TxHash)
+	result = <-done
+	assert.ErrorIs(t, result.err, metax.ErrTxDropped)
+	assert.Equal(t, metax.TxStatusDropped, sub.Status())
+}
+
+func TestWaitNewHeads(t *testing.T) {
+	signer, _ := metax.NewSignerFromMnemonic(testMnemonic, "", "m/44'/60'/0'/0/0")
+	var chain *fakeChain
+	b, chain, stop := buildSubmitBcnmy(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metax.MetaTxResponse{TxHash: chain.mine(nil)})
+	})
+	defer stop()
+
+	sub, err := b.Submit(signer, "transfer", transferParams()...)
+	assert.Nil(t, err)
+	head, _ := chain.HeaderByNumber(context.Background(), nil)
+	chain.include(sub.TxHash, head.Number.Int64()+1)
+	chain.Commit()
+
+	// the poll interval is never reached, new heads wake the waiter
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := b.WaitConfirmed(ctx, sub.TxHash, &metax.WaitOpts{Confirmations: 2, PollInterval: time.Hour})
+		done <- err
+	}()
+	for waiting := true; waiting; {
+		select {
+		case err = <-done:
+			waiting = false
+		case <-time.After(20 * time.Millisecond):
+			chain.Commit()
+		}
+	}
+	assert.Nil(t, err)
+
+	_, err = metax.New("", metax.WithRPC("http://localhost:8545"), metax.WithPollInterval(0))
+	assert.NotNil(t, err)
+}